@@ -35,6 +35,13 @@ const (
 type Test struct {
 	ID     string     `json:"id"`
 	Result TestResult `json:"result,omitempty"`
+
+	App     string `json:"app,omitempty"`
+	Cluster string `json:"cluster,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	StartedAt  *metav1.Time `json:"startedAt,omitempty"`
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
 }
 
 //+kubebuilder:object:root=true
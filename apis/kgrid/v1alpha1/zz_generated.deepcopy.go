@@ -321,7 +321,9 @@ func (in *Outcome) DeepCopyInto(out *Outcome) {
 	if in.Tests != nil {
 		in, out := &in.Tests, &out.Tests
 		*out = make([]Test, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -434,6 +436,14 @@ func (in *SlackLogger) DeepCopy() *SlackLogger {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Test) DeepCopyInto(out *Test) {
 	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Test.
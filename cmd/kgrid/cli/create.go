@@ -23,32 +23,36 @@ func CreateCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) (testError error) {
 			v := viper.GetViper()
 
-			if v.GetString("like") != "" {
-				testError = errors.New("like is not yet supported")
-				return
-			}
-
-			data, err := ioutil.ReadFile(v.GetString("from-yaml"))
+			store, err := getConfigStore(v)
 			if err != nil {
-				testError = errors.Wrap(err, "failed to read from-yaml file")
+				testError = errors.Wrap(err, "failed to open config store")
 				return
 			}
 
-			gridSpec := types.Grid{}
-			if err := yaml.Unmarshal(data, &gridSpec); err != nil {
-				testError = errors.Wrapf(err, "failed to unmarshal %s", v.GetString("from-yaml"))
-				return
+			// with resume, the grid and its clusters come from the config instead of a spec
+			var gridSpec *types.Grid
+			var log logger.Logger
+			gridName := v.GetString("resume")
+			if gridName != "" {
+				if v.GetString("from-yaml") != "" || v.GetString("like") != "" {
+					testError = errors.New("resume can't be used with from-yaml or like")
+					return
+				}
+				log, err = newLogger(v, types.LoggerSpec{}, gridName, "")
+			} else {
+				gridSpec, err = loadGridSpec(v, store)
+				if err != nil {
+					testError = err
+					return
+				}
+				gridName = gridSpec.Name
+				log, err = gridSpecLogger(v, gridSpec)
 			}
-
-			if len(gridSpec.Spec.Clusters) == 0 {
-				testError = errors.New("no clusters defined in spec")
+			if err != nil {
+				testError = err
 				return
 			}
 
-			if v.GetString("name") != "" {
-				gridSpec.Name = v.GetString("name")
-			}
-
 			var application *types.Application
 			if v.GetString("app") != "" {
 				data, err := ioutil.ReadFile(v.GetString("app"))
@@ -64,15 +68,14 @@ func CreateCmd() *cobra.Command {
 				}
 			}
 
-			log := logger.NewLogger(gridSpec.Spec.Clusters[0].Logger)
 			if application == nil {
-				log.StartThread("Creating clusters for %s", gridSpec.Name)
+				log.StartThread("Creating clusters for %s", gridName)
 				defer func() {
 					resultMark := ":white_check_mark:"
 					if testError != nil {
 						resultMark = ":x:"
 					}
-					log.FinishThread("%s Creating clusters for %s", resultMark, gridSpec.Name)
+					log.FinishThread("%s Creating clusters for %s", resultMark, gridName)
 				}()
 			} else {
 				log.StartThread("Testing app %s", getAppDisplayName(*application))
@@ -81,12 +84,23 @@ func CreateCmd() *cobra.Command {
 					if testError != nil {
 						resultMark = ":x:"
 					}
+					log.TestResult(getAppDisplayName(*application), testError)
 					log.FinishThread("%s Testing app %s", resultMark, getAppDisplayName(*application))
 				}()
 			}
 
-			if err := grid.Create(v.GetString("config-file"), &gridSpec, log); err != nil {
+			createOpts := grid.CreateOptions{
+				KeepOnFailure: v.GetBool("keep-on-failure"),
+			}
+			if gridSpec == nil {
+				if err := grid.Resume(cmd.Context(), store, gridName, createOpts, log); err != nil {
+					testError = errors.Wrap(err, "failed to resume cluster")
+					logResumeHint(log, createOpts, gridName)
+					return
+				}
+			} else if err := grid.Create(cmd.Context(), store, gridSpec, createOpts, log); err != nil {
 				testError = errors.Wrap(err, "failed to create cluster")
+				logResumeHint(log, createOpts, gridName)
 				return
 			}
 
@@ -94,7 +108,7 @@ func CreateCmd() *cobra.Command {
 				return
 			}
 
-			if err := deployApp(v.GetString("config-file"), gridSpec.Name, v.GetString("app"), log); err != nil {
+			if err := deployApp(cmd.Context(), store, gridName, v.GetString("app"), waitSpecFromFlags(v), log); err != nil {
 				testError = errors.Wrap(err, "failed to deploy app")
 				return
 			}
@@ -106,14 +120,68 @@ func CreateCmd() *cobra.Command {
 	cmd.Flags().StringP("name", "n", "", "Name of the grid, overriding the name in the yaml metadata.name field")
 	cmd.Flags().String("from-yaml", "", "Path to YAML manifest describing the grid to create")
 	cmd.Flags().String("like", "", "Name of an existing grid to clone, into a new grid")
+	cmd.Flags().String("region", "", "Region for new clusters when cloning a grid with --like, overriding the cloned region")
+	cmd.Flags().String("version", "", "Kubernetes version for new clusters when cloning a grid with --like, overriding the cloned version")
+	cmd.Flags().String("resume", "", "Name of a grid whose interrupted create should be finished, skipping completed steps")
+	cmd.Flags().Bool("keep-on-failure", false, "Keep clusters that fail or are interrupted, so create --resume can finish them, instead of deleting them")
 	cmd.Flags().String("app", "", "Path to YAML manifest describing the application to deploy after grid is created")
+	addWaitFlags(cmd)
 
 	return cmd
 }
 
+// loadGridSpec reads the grid spec from --from-yaml, or reconstructs it from the grid named by --like
+func loadGridSpec(v *viper.Viper, store grid.ConfigStore) (*types.Grid, error) {
+	var gridSpec *types.Grid
+	if v.GetString("like") != "" {
+		if v.GetString("from-yaml") != "" {
+			return nil, errors.New("only one of from-yaml and like can be specified")
+		}
+		if v.GetString("name") == "" {
+			return nil, errors.New("name is required when cloning a grid with like")
+		}
+
+		s, err := grid.SpecLike(store, v.GetString("like"), v.GetString("name"), grid.LikeOptions{
+			Region:  v.GetString("region"),
+			Version: v.GetString("version"),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to clone grid %s", v.GetString("like"))
+		}
+		gridSpec = s
+	} else {
+		data, err := ioutil.ReadFile(v.GetString("from-yaml"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read from-yaml file")
+		}
+
+		gridSpec = &types.Grid{}
+		if err := yaml.Unmarshal(data, gridSpec); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal %s", v.GetString("from-yaml"))
+		}
+	}
+
+	if len(gridSpec.Spec.Clusters) == 0 {
+		return nil, errors.New("no clusters defined in spec")
+	}
+
+	if v.GetString("name") != "" {
+		gridSpec.Name = v.GetString("name")
+	}
+
+	return gridSpec, nil
+}
+
 func getAppDisplayName(application types.Application) string {
 	if application.Spec.KOTSApplicationSpec != nil {
 		return application.Spec.KOTSApplicationSpec.App
 	}
 	return ""
 }
+
+// logResumeHint says how to finish a failed create whose clusters were kept
+func logResumeHint(log logger.Logger, opts grid.CreateOptions, gridName string) {
+	if opts.KeepOnFailure {
+		log.Info("Unfinished clusters were kept, run kgrid create --resume %s --keep-on-failure to finish them", gridName)
+	}
+}
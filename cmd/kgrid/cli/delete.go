@@ -3,9 +3,9 @@ package cli
 import (
 	"io/ioutil"
 
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
-	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"sigs.k8s.io/yaml"
@@ -13,8 +13,10 @@ import (
 
 func DeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:           "delete",
+		Use:           "delete [grid]",
 		Short:         "Delete a grid",
+		Long:          "Delete a grid, or a single cluster in it with --cluster. Clusters that were connected, not created, by kgrid are only removed from the grid.",
+		Args:          cobra.MaximumNArgs(1),
 		SilenceErrors: true,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			viper.BindPFlags(cmd.Flags())
@@ -22,18 +24,48 @@ func DeleteCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := viper.GetViper()
 
-			gridSpecData, err := ioutil.ReadFile(v.GetString("from-yaml"))
+			store, err := getConfigStore(v)
 			if err != nil {
-				return err
+				return errors.Wrap(err, "failed to open config store")
+			}
+
+			gridName := v.GetString("name")
+			if len(args) > 0 {
+				gridName = args[0]
 			}
 
-			gridSpec := &types.Grid{}
-			if err := yaml.Unmarshal(gridSpecData, gridSpec); err != nil {
+			loggerSpec := types.LoggerSpec{}
+
+			// the yaml is only used for the grid name and logger
+			if v.GetString("from-yaml") != "" {
+				gridSpecData, err := ioutil.ReadFile(v.GetString("from-yaml"))
+				if err != nil {
+					return errors.Wrap(err, "failed to read from-yaml file")
+				}
+
+				gridSpec := &types.Grid{}
+				if err := yaml.Unmarshal(gridSpecData, gridSpec); err != nil {
+					return errors.Wrapf(err, "failed to unmarshal %s", v.GetString("from-yaml"))
+				}
+
+				if gridName == "" {
+					gridName = gridSpec.Name
+				}
+				if len(gridSpec.Spec.Clusters) > 0 {
+					loggerSpec = gridSpec.Spec.Clusters[0].Logger
+				}
+			}
+
+			if gridName == "" {
+				return errors.New("grid name is required")
+			}
+
+			log, err := newLogger(v, loggerSpec, gridName, v.GetString("cluster"))
+			if err != nil {
 				return err
 			}
 
-			log := logger.NewLogger(gridSpec.Spec.Clusters[0].Logger)
-			if err := grid.Delete(v.GetString("config-file"), gridSpec, log); err != nil {
+			if err := grid.Delete(cmd.Context(), store, gridName, v.GetString("cluster"), log); err != nil {
 				return err
 			}
 
@@ -41,9 +73,9 @@ func DeleteCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringP("name", "n", "", "Name of the grid, overriding the name in the yaml metadata.name field")
-	cmd.Flags().String("from-yaml", "", "Path to YAML manifest describing the grid to delete")
-	cmd.Flags().String("like", "", "Name of an existing grid to clone, into a new grid")
+	cmd.Flags().StringP("name", "n", "", "Name of the grid to delete")
+	cmd.Flags().String("from-yaml", "", "Path to the YAML manifest the grid was created from, used for the grid name and logger")
+	cmd.Flags().String("cluster", "", "Name of a single cluster in the grid to delete")
 
 	return cmd
 }
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"context"
 	"io/ioutil"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/app"
@@ -10,6 +12,7 @@ import (
 	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -24,17 +27,28 @@ func DeployCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := viper.GetViper()
 
-			return deployApp(v.GetString("config-file"), v.GetString("grid"), v.GetString("app"), logger.NewTerminalLogger())
+			store, err := getConfigStore(v)
+			if err != nil {
+				return errors.Wrap(err, "failed to open config store")
+			}
+
+			log, err := newLogger(v, types.LoggerSpec{}, v.GetString("grid"), "")
+			if err != nil {
+				return err
+			}
+
+			return deployApp(cmd.Context(), store, v.GetString("grid"), v.GetString("app"), waitSpecFromFlags(v), log)
 		},
 	}
 
 	cmd.Flags().StringP("grid", "g", "", "Name of the grid")
 	cmd.Flags().String("app", "", "Path to YAML manifest describing the application to deploy")
+	addWaitFlags(cmd)
 
 	return cmd
 }
 
-func deployApp(configFile string, gridName string, appSpecFilename string, log logger.Logger) error {
+func deployApp(ctx context.Context, store grid.ConfigStore, gridName string, appSpecFilename string, wait *types.WaitSpec, log logger.Logger) error {
 	data, err := ioutil.ReadFile(appSpecFilename)
 	if err != nil {
 		return errors.Wrap(err, "failed to read app spec file")
@@ -44,15 +58,16 @@ func deployApp(configFile string, gridName string, appSpecFilename string, log l
 	if err := yaml.Unmarshal(data, &application); err != nil {
 		return errors.Wrap(err, "failed to unmarshal app spec")
 	}
+	application.Spec.Wait = mergeWaitSpec(application.Spec.Wait, wait)
 
-	grids, err := grid.List(configFile)
+	grids, err := grid.List(store)
 	if err != nil {
 		return errors.Wrap(err, "failed to list grids")
 	}
 
 	for _, g := range grids {
 		if g.Name == gridName {
-			if err := app.Deploy(g, &application, log); err != nil {
+			if err := app.Deploy(ctx, g, &application, log); err != nil {
 				return errors.Wrap(err, "failed to deploy app")
 			}
 
@@ -62,3 +77,100 @@ func deployApp(configFile string, gridName string, appSpecFilename string, log l
 
 	return errors.New("unable to find grid")
 }
+
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("install-timeout", 0, "How long to wait for the application install to complete (default 10m)")
+	cmd.Flags().Duration("ready-timeout", 0, "How long to wait for the application to become ready after install (default 5m)")
+	cmd.Flags().Duration("status-timeout", 0, "How long a single application status check can take (default 20s)")
+	cmd.Flags().Duration("poll-interval", 0, "How often to check the application status (default 10s)")
+	cmd.Flags().Float64("backoff-factor", 0, "Multiply the poll interval by this factor after each status check")
+	cmd.Flags().Duration("max-poll-interval", 0, "Upper limit for the poll interval when backing off")
+	cmd.Flags().StringSlice("ready-state", []string{}, "Application states that count as ready (default ready)")
+	cmd.Flags().StringSlice("tolerate-degraded", []string{}, "Resources that are allowed to be degraded when the app is ready, as [namespace/][kind/]name")
+}
+
+// waitSpecFromFlags returns a wait spec containing only the values that were set on the command line
+func waitSpecFromFlags(v *viper.Viper) *types.WaitSpec {
+	wait := &types.WaitSpec{}
+
+	if d := v.GetDuration("install-timeout"); d > 0 {
+		wait.InstallTimeout = &metav1.Duration{Duration: d}
+	}
+	if d := v.GetDuration("ready-timeout"); d > 0 {
+		wait.ReadyTimeout = &metav1.Duration{Duration: d}
+	}
+	if d := v.GetDuration("status-timeout"); d > 0 {
+		wait.StatusTimeout = &metav1.Duration{Duration: d}
+	}
+	if d := v.GetDuration("poll-interval"); d > 0 {
+		wait.PollInterval = &metav1.Duration{Duration: d}
+	}
+
+	factor := v.GetFloat64("backoff-factor")
+	maxInterval := v.GetDuration("max-poll-interval")
+	if factor > 0 || maxInterval > 0 {
+		wait.Backoff = &types.BackoffSpec{
+			Factor: factor,
+		}
+		if maxInterval > 0 {
+			wait.Backoff.MaxInterval = &metav1.Duration{Duration: maxInterval}
+		}
+	}
+
+	readyStates := v.GetStringSlice("ready-state")
+	tolerateDegraded := v.GetStringSlice("tolerate-degraded")
+	if len(readyStates) > 0 || len(tolerateDegraded) > 0 {
+		wait.Ready = &types.ReadyCriteria{
+			States: readyStates,
+		}
+		for _, resource := range tolerateDegraded {
+			wait.Ready.TolerateDegraded = append(wait.Ready.TolerateDegraded, parseResourceSelector(resource))
+		}
+	}
+
+	return wait
+}
+
+func parseResourceSelector(s string) types.ResourceSelector {
+	parts := strings.Split(s, "/")
+	switch len(parts) {
+	case 1:
+		return types.ResourceSelector{Name: parts[0]}
+	case 2:
+		return types.ResourceSelector{Kind: parts[0], Name: parts[1]}
+	default:
+		return types.ResourceSelector{Namespace: parts[0], Kind: parts[1], Name: strings.Join(parts[2:], "/")}
+	}
+}
+
+// mergeWaitSpec returns spec with any values set in override replacing the values in spec
+func mergeWaitSpec(spec *types.WaitSpec, override *types.WaitSpec) *types.WaitSpec {
+	if override == nil {
+		return spec
+	}
+	if spec == nil {
+		return override
+	}
+
+	merged := *spec
+	if override.InstallTimeout != nil {
+		merged.InstallTimeout = override.InstallTimeout
+	}
+	if override.ReadyTimeout != nil {
+		merged.ReadyTimeout = override.ReadyTimeout
+	}
+	if override.StatusTimeout != nil {
+		merged.StatusTimeout = override.StatusTimeout
+	}
+	if override.PollInterval != nil {
+		merged.PollInterval = override.PollInterval
+	}
+	if override.Backoff != nil {
+		merged.Backoff = override.Backoff
+	}
+	if override.Ready != nil {
+		merged.Ready = override.Ready
+	}
+
+	return &merged
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"os"
 
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -21,9 +22,10 @@ func DescribeCmd() *cobra.Command {
 		},
 	}
 
-	cmd.PersistentFlags().StringP("output", "o", "", "Output format (empty or json)")
+	cmd.PersistentFlags().StringP("output", "o", "", print.FormatUsage)
 
 	cmd.AddCommand(DescribeGridCmd())
+	cmd.AddCommand(DescribeOutcomeCmd())
 
 	return cmd
 }
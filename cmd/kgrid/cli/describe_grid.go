@@ -2,18 +2,28 @@ package cli
 
 import (
 	"fmt"
-	"strings"
+	"io"
 
-	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/health"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// gridDescription is the grid config along with what each cluster reported when it was checked
+type gridDescription struct {
+	*types.GridConfig
+	Health []*health.ClusterHealth `json:"health"`
+}
+
 func DescribeGridCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:           "grid",
+		Use:           "grid [name]",
 		Short:         "Describe a grid",
+		Long:          "Describe a grid, asking each cluster for its version, nodes and deployed apps. Clusters that don't answer within --timeout are shown as unreachable.",
+		Args:          cobra.ExactArgs(1),
 		SilenceErrors: true,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			viper.BindPFlags(cmd.Flags())
@@ -22,44 +32,72 @@ func DescribeGridCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := viper.GetViper()
 
-			grids, err := grid.List(v.GetString("config-file"))
-			if err != nil {
+			format := v.GetString("output")
+			if err := print.ValidateFormat(format); err != nil {
 				return err
 			}
 
-			for _, g := range grids {
-				if g.Name == args[0] {
-					if v.GetString("output") == "json" {
-						printJSONGridDescription(g)
-					} else {
-						printTextGridDescription(g)
-					}
-				}
+			store, err := getConfigStore(v)
+			if err != nil {
+				return errors.Wrap(err, "failed to open config store")
 			}
 
-			return nil
+			g, err := findGrid(store, args[0])
+			if err != nil {
+				return err
+			}
+
+			d := gridDescription{
+				GridConfig: print.RedactGridConfig(g),
+				Health:     checkHealth(cmd.Context(), g.Name, g.ClusterConfigs, healthOptions(v)),
+			}
+			return print.PrintObject(cmd.OutOrStdout(), format, d, func(w io.Writer) error {
+				return printGridDescription(w, d, format == print.FormatWide)
+			})
 		},
 	}
 
+	addHealthFlags(cmd)
+
 	return cmd
 }
 
-func printTextGridDescription(g *types.GridConfig) {
-	clusters := []string{}
-	for _, c := range g.ClusterConfigs {
-		renderedCluster := fmt.Sprintf("  - Name: %s\n    Provider: %s\n",
-			c.Name, c.Provider)
+func printGridDescription(w io.Writer, d gridDescription, wide bool) error {
+	fmt.Fprintf(w, "Name: %s\n\nClusters:\n", d.Name)
 
-		clusters = append(clusters, renderedCluster)
+	format := print.FormatTable
+	if wide {
+		format = print.FormatWide
+	}
+	if err := print.PrintList(w, format, d.Health, print.ClusterHealthTable(now())); err != nil {
+		return err
 	}
 
-	fmt.Printf(`Grid Name: %s
-Clusters:
-%s	
-`,
-		g.Name, strings.Join(clusters, "\n"))
-}
+	apps := []print.AppRow{}
+	errs := []string{}
+	for _, h := range d.Health {
+		for _, a := range h.Apps {
+			apps = append(apps, print.AppRow{Cluster: h.Name, Namespace: a.Namespace, Slug: a.Slug, State: a.State})
+		}
+		if h.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", h.Name, h.Error))
+		}
+		if h.AppsError != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", h.Name, h.AppsError))
+		}
+	}
+
+	fmt.Fprintf(w, "\nApps:\n")
+	if err := print.PrintList(w, print.FormatTable, apps, print.AppsTable); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(w, "\nErrors:\n")
+		for _, e := range errs {
+			fmt.Fprintf(w, "  %s\n", e)
+		}
+	}
 
-func printJSONGridDescription(g *types.GridConfig) {
-	fmt.Printf("not implemented\n")
+	return nil
 }
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	kgridv1alpha1 "github.com/replicatedhq/kgrid/apis/kgrid/v1alpha1"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// outcomeDescription is a test run and the tests in it
+type outcomeDescription struct {
+	RunID     string               `json:"runId"`
+	Namespace string               `json:"namespace"`
+	Status    string               `json:"status"`
+	Tests     []outcome.TestStatus `json:"tests"`
+}
+
+func DescribeOutcomeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "outcome [run id]",
+		Short:         "Describe a test run of the kgrid operator",
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			format := v.GetString("output")
+			if err := print.ValidateFormat(format); err != nil {
+				return err
+			}
+
+			clients, err := getOperatorClients(v)
+			if err != nil {
+				return err
+			}
+
+			o, err := clients.Kgrid.Outcomes(clients.Namespace).Get(cmd.Context(), args[0], metav1.GetOptions{})
+			if kuberneteserrors.IsNotFound(err) {
+				return errors.Errorf("outcome %s not found", args[0])
+			} else if err != nil {
+				return errors.Wrap(err, "failed to get outcome")
+			}
+
+			printOutcome := func(o *kgridv1alpha1.Outcome) error {
+				d := outcomeDescription{
+					RunID:     o.Name,
+					Namespace: o.Namespace,
+					Status:    print.OutcomeStatus(o),
+					Tests:     outcome.TestStatuses(o),
+				}
+				return print.PrintObject(cmd.OutOrStdout(), format, d, func(w io.Writer) error {
+					return printOutcomeDescription(w, d, format == print.FormatWide)
+				})
+			}
+			if err := printOutcome(o); err != nil {
+				return err
+			}
+
+			if !v.GetBool("watch") || outcome.IsFinalized(outcome.TestStatuses(o)) {
+				return nil
+			}
+
+			return outcome.WatchOutcomes(cmd.Context(), clients.Kgrid, clients.Namespace, o.Name, o.ResourceVersion, func(o *kgridv1alpha1.Outcome) (bool, error) {
+				fmt.Fprintln(cmd.OutOrStdout())
+				if err := printOutcome(o); err != nil {
+					return false, err
+				}
+				return outcome.IsFinalized(outcome.TestStatuses(o)), nil
+			})
+		},
+	}
+
+	addWatchFlag(cmd)
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+func printOutcomeDescription(w io.Writer, d outcomeDescription, wide bool) error {
+	fmt.Fprintf(w, "Run: %s\nNamespace: %s\nStatus: %s\n\nTests:\n", d.RunID, d.Namespace, d.Status)
+
+	format := print.FormatTable
+	if wide {
+		format = print.FormatWide
+	}
+	return print.PrintList(w, format, d.Tests, print.TestsTable(now()))
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"os"
 
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,9 +24,12 @@ func GetCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringP("grid", "g", "", "Name of the grid")
 	cmd.PersistentFlags().StringP("cluster", "c", "", "Name of the cluster")
-	cmd.PersistentFlags().StringP("output", "o", "", "Output format (empty or json)")
+	cmd.PersistentFlags().StringP("output", "o", "", print.FormatUsage)
 
 	cmd.AddCommand(GetGridsCmd())
+	cmd.AddCommand(GetClustersCmd())
+	cmd.AddCommand(GetOutcomesCmd())
+	cmd.AddCommand(GetTestsCmd())
 	cmd.AddCommand(GetNamespacesCmd())
 
 	return cmd
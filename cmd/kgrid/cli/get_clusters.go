@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/app"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/health"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// checkHealth and now are replaced in tests, so output doesn't depend on live clusters or the clock
+var (
+	checkHealth = health.CheckAll
+	now         = time.Now
+)
+
+func GetClustersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "clusters",
+		Aliases: []string{
+			"cluster",
+		},
+		Short:         "List the clusters in a grid and check their health",
+		Long:          "List the clusters in a grid, asking each one for its version, nodes and deployed apps. Clusters that don't answer within --timeout are shown as unreachable.",
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			format := v.GetString("output")
+			if err := print.ValidateFormat(format); err != nil {
+				return err
+			}
+
+			gridName := v.GetString("grid")
+			if gridName == "" {
+				return errors.New("--grid is required")
+			}
+
+			store, err := getConfigStore(v)
+			if err != nil {
+				return errors.Wrap(err, "failed to open config store")
+			}
+
+			clusterConfigs, err := selectGridClusters(store, gridName, v.GetString("cluster"), true)
+			if err != nil {
+				return err
+			}
+
+			results := checkHealth(cmd.Context(), gridName, clusterConfigs, healthOptions(v))
+			return print.PrintList(cmd.OutOrStdout(), format, results, print.ClusterHealthTable(now()))
+		},
+	}
+
+	addHealthFlags(cmd)
+
+	return cmd
+}
+
+func addHealthFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", health.DefaultTimeout, "How long to wait for each cluster to respond")
+}
+
+func healthOptions(v *viper.Viper) health.Options {
+	return health.Options{
+		Timeout:    v.GetDuration("timeout"),
+		KOTSBinary: app.GetKOTSBinary,
+	}
+}
+
+func findGrid(store grid.ConfigStore, gridName string) (*types.GridConfig, error) {
+	grids, err := grid.List(store)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range grids {
+		if g.Name == gridName {
+			return g, nil
+		}
+	}
+
+	return nil, errors.Errorf("grid %s not found", gridName)
+}
@@ -1,9 +1,7 @@
 package cli
 
 import (
-	"encoding/json"
-	"fmt"
-
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
@@ -25,41 +23,28 @@ func GetGridsCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := viper.GetViper()
 
-			grids, err := grid.List(v.GetString("config-file"))
+			if err := print.ValidateFormat(v.GetString("output")); err != nil {
+				return err
+			}
+
+			store, err := getConfigStore(v)
+			if err != nil {
+				return errors.Wrap(err, "failed to open config store")
+			}
+
+			grids, err := grid.List(store)
 			if err != nil {
 				return err
 			}
 
-			if v.GetString("output") == "json" {
-				printGridsJSON(grids)
-			} else {
-				printGridsTable(grids)
+			redactedGrids := []*types.GridConfig{}
+			for _, g := range grids {
+				redactedGrids = append(redactedGrids, print.RedactGridConfig(g))
 			}
 
-			return nil
+			return print.PrintList(cmd.OutOrStdout(), v.GetString("output"), redactedGrids, print.GridsTable)
 		},
 	}
 
 	return cmd
 }
-
-func printGridsJSON(grids []*types.GridConfig) {
-	str, _ := json.MarshalIndent(grids, "", "    ")
-	fmt.Println(string(str))
-}
-
-func printGridsTable(grids []*types.GridConfig) {
-	if len(grids) == 0 {
-		fmt.Println("No grids found")
-		return
-	}
-
-	w := print.NewTabWriter()
-	defer w.Flush()
-
-	fmtColumns := "%s\n"
-	fmt.Fprintf(w, fmtColumns, "NAME")
-	for _, g := range grids {
-		fmt.Fprintf(w, fmtColumns, g.Name)
-	}
-}
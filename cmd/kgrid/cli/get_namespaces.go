@@ -1,18 +1,26 @@
 package cli
 
 import (
-	"errors"
-	"fmt"
-	"os"
+	"context"
+	"time"
 
+	"github.com/pkg/errors"
+	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
-	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/cli-runtime/pkg/printers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// getClusterClients is replaced in tests, so namespaces come from fake clientsets
+var getClusterClients = cluster.GetClients
+
+// namespaceConcurrency is the max number of clusters asked for their namespaces at the same time
+const namespaceConcurrency = 8
+
 func GetNamespacesCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "namespaces",
@@ -20,7 +28,8 @@ func GetNamespacesCmd() *cobra.Command {
 			"namespace",
 			"ns",
 		},
-		Short:         "List the namespaces in a cluster on the grid",
+		Short:         "List the namespaces in the clusters on the grid",
+		Long:          "List the namespaces in every cluster on the grid, or only in --cluster, with a row for each cluster and namespace.",
 		SilenceErrors: true,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			viper.BindPFlags(cmd.Flags())
@@ -28,57 +37,80 @@ func GetNamespacesCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := viper.GetViper()
 
-			grids, err := grid.List(v.GetString("config-file"))
-			if err != nil {
+			format := v.GetString("output")
+			if err := print.ValidateFormat(format); err != nil {
 				return err
 			}
 
-			for _, g := range grids {
-				if g.Name == v.GetString("grid") {
-					for _, c := range g.ClusterConfigs {
-						if c.Name == v.GetString("cluster") {
-							namespaces, err := cluster.ListNamespaces(c)
-							if err != nil {
-								return err
-							}
-
-							if v.GetString("output") == "json" {
-								printNamespacesJSON(namespaces)
-							} else {
-								printNamespacesTable(namespaces)
-							}
-
-							return nil
-						}
-					}
-
-					return errors.New("cluster not found")
-				}
+			store, err := getConfigStore(v)
+			if err != nil {
+				return errors.Wrap(err, "failed to open config store")
 			}
 
-			return errors.New("grid not found")
+			gridName := v.GetString("grid")
+			clusterConfigs, err := selectClusters(store, gridName, v.GetString("cluster"))
+			if err != nil {
+				return err
+			}
+
+			rows, listErr := listNamespaces(cmd.Context(), gridName, clusterConfigs, v.GetDuration("timeout"))
+			if err := print.PrintList(cmd.OutOrStdout(), format, rows, print.NamespacesTable(now())); err != nil {
+				return err
+			}
+			return listErr
 		},
 	}
 
-	cmd.Flags().StringP("cluster", "c", "", "The name of the cluster to get namespaces in")
-
-	cmd.MarkFlagRequired("cluster")
+	addHealthFlags(cmd)
 
 	return cmd
 }
 
-func printNamespacesTable(namespaces *corev1.NamespaceList) {
-	if len(namespaces.Items) == 0 {
-		fmt.Println("No namespaces found")
-		return
-	}
+// listNamespaces returns the namespaces in each cluster, in cluster order.
+// Clusters that can't be listed are left out, and returned in the error.
+func listNamespaces(ctx context.Context, gridName string, clusterConfigs []*types.ClusterConfig, timeout time.Duration) ([]print.NamespaceRow, error) {
+	tasks := []parallel.Task[[]print.NamespaceRow]{}
+	for _, c := range clusterConfigs {
+		c := c
+		tasks = append(tasks, func(ctx context.Context) ([]print.NamespaceRow, error) {
+			clients, err := getClusterClients(gridName, c)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get cluster clients")
+			}
 
-	p := printers.NewTablePrinter(printers.PrintOptions{})
-	for _, ns := range namespaces.Items {
-		p.PrintObj(&ns, os.Stdout)
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			namespaces, err := clients.Kubernetes.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list namespaces")
+			}
+
+			rows := []print.NamespaceRow{}
+			for _, ns := range namespaces.Items {
+				rows = append(rows, print.NamespaceRow{
+					Cluster:   c.Name,
+					Name:      ns.Name,
+					Status:    string(ns.Status.Phase),
+					CreatedAt: ns.CreationTimestamp,
+				})
+			}
+			return rows, nil
+		})
 	}
-}
 
-func printNamespacesJSON(namespaces *corev1.NamespaceList) {
+	rows := []print.NamespaceRow{}
+	listErrors := []error{}
+	for _, result := range parallel.Run(ctx, namespaceConcurrency, tasks) {
+		if result.Err != nil {
+			listErrors = append(listErrors, errors.Wrapf(result.Err, "cluster %s", clusterConfigs[result.Index].Name))
+			continue
+		}
+		rows = append(rows, result.Value...)
+	}
+	if len(listErrors) > 0 {
+		return rows, &kerrors.MultiError{Errors: listErrors}
+	}
 
+	return rows, nil
 }
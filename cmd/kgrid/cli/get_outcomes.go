@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	kgridv1alpha1 "github.com/replicatedhq/kgrid/apis/kgrid/v1alpha1"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func GetOutcomesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "outcomes [run id]",
+		Aliases: []string{
+			"outcome",
+		},
+		Short:         "List the test runs of the kgrid operator and their results",
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			format := v.GetString("output")
+			if err := print.ValidateFormat(format); err != nil {
+				return err
+			}
+
+			clients, err := getOperatorClients(v)
+			if err != nil {
+				return err
+			}
+
+			runID := ""
+			if len(args) > 0 {
+				runID = args[0]
+			}
+
+			outcomes, err := clients.Kgrid.Outcomes(clients.Namespace).List(cmd.Context(), metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to list outcomes")
+			}
+
+			byName := map[string]*kgridv1alpha1.Outcome{}
+			for i := range outcomes.Items {
+				if runID == "" || outcomes.Items[i].Name == runID {
+					byName[outcomes.Items[i].Name] = &outcomes.Items[i]
+				}
+			}
+			if runID != "" && len(byName) == 0 {
+				return errors.Errorf("outcome %s not found", runID)
+			}
+
+			printOutcomes := func() error {
+				return print.PrintList(cmd.OutOrStdout(), format, sortedOutcomes(byName), print.OutcomesTable(now()))
+			}
+			if err := printOutcomes(); err != nil {
+				return err
+			}
+
+			if !v.GetBool("watch") || outcomesFinalized(byName) {
+				return nil
+			}
+
+			return outcome.WatchOutcomes(cmd.Context(), clients.Kgrid, clients.Namespace, runID, outcomes.ResourceVersion, func(o *kgridv1alpha1.Outcome) (bool, error) {
+				byName[o.Name] = o
+				fmt.Fprintln(cmd.OutOrStdout())
+				if err := printOutcomes(); err != nil {
+					return false, err
+				}
+				return outcomesFinalized(byName), nil
+			})
+		},
+	}
+
+	addWatchFlag(cmd)
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+func sortedOutcomes(byName map[string]*kgridv1alpha1.Outcome) []*kgridv1alpha1.Outcome {
+	outcomes := []*kgridv1alpha1.Outcome{}
+	for _, o := range byName {
+		outcomes = append(outcomes, o)
+	}
+	sort.Slice(outcomes, func(i, j int) bool {
+		if !outcomes[i].CreationTimestamp.Equal(&outcomes[j].CreationTimestamp) {
+			return outcomes[i].CreationTimestamp.Before(&outcomes[j].CreationTimestamp)
+		}
+		return outcomes[i].Name < outcomes[j].Name
+	})
+	return outcomes
+}
+
+func outcomesFinalized(byName map[string]*kgridv1alpha1.Outcome) bool {
+	for _, o := range byName {
+		if !outcome.IsFinalized(outcome.TestStatuses(o)) {
+			return false
+		}
+	}
+	return true
+}
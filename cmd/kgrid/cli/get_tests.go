@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func GetTestsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "tests",
+		Aliases: []string{
+			"test",
+		},
+		Short:         "List the test pods of the kgrid operator",
+		Long:          "List the test pods that the kgrid operator started, for every app or only for --app.",
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			format := v.GetString("output")
+			if err := print.ValidateFormat(format); err != nil {
+				return err
+			}
+
+			clients, err := getOperatorClients(v)
+			if err != nil {
+				return err
+			}
+
+			app := v.GetString("app")
+			tests, resourceVersion, err := outcome.ListTests(cmd.Context(), clients.Kubernetes, clients.Namespace, app)
+			if err != nil {
+				return err
+			}
+
+			byID := map[string]outcome.TestStatus{}
+			for _, t := range tests {
+				byID[t.ID] = t
+			}
+
+			printTests := func() error {
+				return print.PrintList(cmd.OutOrStdout(), format, sortedTests(byID), print.TestsTable(now()))
+			}
+			if err := printTests(); err != nil {
+				return err
+			}
+
+			if !v.GetBool("watch") || outcome.IsFinalized(tests) {
+				return nil
+			}
+
+			return outcome.WatchTests(cmd.Context(), clients.Kubernetes, clients.Namespace, app, resourceVersion, func(t outcome.TestStatus) (bool, error) {
+				byID[t.ID] = t
+				fmt.Fprintln(cmd.OutOrStdout())
+				if err := printTests(); err != nil {
+					return false, err
+				}
+				return outcome.IsFinalized(sortedTests(byID)), nil
+			})
+		},
+	}
+
+	cmd.Flags().String("app", "", "Only list the tests for this application")
+	addWatchFlag(cmd)
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+func sortedTests(byID map[string]outcome.TestStatus) []outcome.TestStatus {
+	tests := []outcome.TestStatus{}
+	for _, t := range byID {
+		tests = append(tests, t)
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		if tests[i].App != tests[j].App {
+			return tests[i].App < tests[j].App
+		}
+		if tests[i].Cluster != tests[j].Cluster {
+			return tests[i].Cluster < tests[j].Cluster
+		}
+		return tests[i].ID < tests[j].ID
+	})
+	return tests
+}
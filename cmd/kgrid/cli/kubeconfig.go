@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func KubeconfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kubeconfig",
+		Short: "Export the kubeconfig for clusters in a grid",
+		Long: `Print the kubeconfig for the clusters in a grid, or write it to --file.
+With --merge, the contexts are added to ~/.kube/config (or --file) as grid-<grid>-<cluster>, and are removed again when the cluster is deleted.
+Merged contexts don't include the grid's AWS keys, they use your default AWS credentials or --profile.`,
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			store, err := getConfigStore(v)
+			if err != nil {
+				return errors.Wrap(err, "failed to open config store")
+			}
+
+			clusterConfigs, err := selectClusters(store, v.GetString("grid"), v.GetString("cluster"))
+			if err != nil {
+				return err
+			}
+
+			kubeconfig, err := cluster.Kubeconfig(v.GetString("grid"), clusterConfigs)
+			if err != nil {
+				return errors.Wrap(err, "failed to build kubeconfig")
+			}
+
+			if v.GetBool("merge") {
+				path := v.GetString("file")
+				if path == "" {
+					path = cluster.MergedKubeconfigPath
+				}
+				if err := cluster.MergeKubeconfig(path, kubeconfig, v.GetString("profile")); err != nil {
+					return errors.Wrapf(err, "failed to merge kubeconfig into %s", path)
+				}
+				clusterNames := []string{}
+				for _, c := range clusterConfigs {
+					clusterNames = append(clusterNames, c.Name)
+				}
+				if err := grid.RecordMergedKubeconfig(store, v.GetString("grid"), path, clusterNames...); err != nil {
+					return errors.Wrap(err, "failed to record merged kubeconfig")
+				}
+				for _, c := range clusterConfigs {
+					fmt.Fprintf(cmd.OutOrStdout(), "Added context %s to %s\n", cluster.ContextName(v.GetString("grid"), c.Name), path)
+				}
+				return nil
+			}
+
+			if v.GetString("file") != "" {
+				if err := clientcmd.WriteToFile(*kubeconfig, v.GetString("file")); err != nil {
+					return errors.Wrap(err, "failed to write kubeconfig")
+				}
+				return nil
+			}
+
+			b, err := clientcmd.Write(*kubeconfig)
+			if err != nil {
+				return errors.Wrap(err, "failed to serialize kubeconfig")
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("grid", "g", "", "Name of the grid")
+	cmd.Flags().StringP("cluster", "c", "", "The name of a single cluster to export, instead of every cluster in the grid")
+	cmd.Flags().Bool("merge", false, "Merge the contexts into ~/.kube/config, or --file, instead of printing the kubeconfig")
+	cmd.Flags().String("file", "", "Write the kubeconfig to this file instead of printing it")
+	cmd.Flags().String("profile", "", "The AWS profile that merged EKS contexts get tokens with")
+
+	cmd.MarkFlagRequired("grid")
+
+	return cmd
+}
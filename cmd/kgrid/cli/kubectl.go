@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/kubectl"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func KubectlCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kubectl -- [kubectl args]",
+		Short: "Run kubectl against every cluster in a grid",
+		Long: `Run kubectl against every cluster in a grid in parallel, prefixing each line of output with the cluster name.
+The exit code is the highest exit code from any cluster.`,
+		Example:       "  kgrid kubectl --grid my-grid -- get pods -A",
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execOnGrid(cmd, kubectl.Binary, args)
+		},
+	}
+
+	addExecFlags(cmd)
+
+	return cmd
+}
+
+func ExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec -- command [args]",
+		Short: "Run a command against every cluster in a grid",
+		Long: `Run a command once per cluster in a grid in parallel, with KUBECONFIG set to that cluster's kubeconfig.
+Each line of output is prefixed with the cluster name, and the exit code is the highest exit code from any cluster.`,
+		Example:       "  kgrid exec --grid my-grid -- helm list -A",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execOnGrid(cmd, args[0], args[1:])
+		},
+	}
+
+	addExecFlags(cmd)
+
+	return cmd
+}
+
+func addExecFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("grid", "g", "", "Name of the grid")
+	cmd.Flags().StringP("cluster", "c", "", "Only run against the cluster with this name")
+
+	cmd.MarkFlagRequired("grid")
+}
+
+func execOnGrid(cmd *cobra.Command, command string, args []string) error {
+	v := viper.GetViper()
+
+	// check once up front rather than failing the same way on every cluster
+	if _, err := exec.LookPath(command); err != nil {
+		return errors.Errorf("%s was not found, install it or add it to PATH", command)
+	}
+
+	store, err := getConfigStore(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to open config store")
+	}
+
+	clusterConfigs, err := selectClusters(store, v.GetString("grid"), v.GetString("cluster"))
+	if err != nil {
+		return err
+	}
+
+	results := kubectl.ExecOnClusters(cmd.Context(), v.GetString("grid"), clusterConfigs, command, args, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "[%s] %v\n", result.ClusterName, result.Err)
+		}
+	}
+
+	if exitCode := kubectl.ExitCode(results); exitCode != 0 {
+		return &exitCodeError{code: exitCode}
+	}
+
+	return nil
+}
+
+// selectClusters returns the clusters in the grid that have finished creating, or only the named cluster if clusterName is set
+func selectClusters(store grid.ConfigStore, gridName string, clusterName string) ([]*types.ClusterConfig, error) {
+	return selectGridClusters(store, gridName, clusterName, false)
+}
+
+// selectGridClusters is selectClusters for commands that can also show clusters that are still being created
+func selectGridClusters(store grid.ConfigStore, gridName string, clusterName string, includeCreating bool) ([]*types.ClusterConfig, error) {
+	g, err := findGrid(store, gridName)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterConfigs := []*types.ClusterConfig{}
+	for _, c := range g.ClusterConfigs {
+		if clusterName != "" && c.Name != clusterName {
+			continue
+		}
+		if c.Creating && !includeCreating {
+			if clusterName != "" {
+				return nil, errors.Errorf("cluster %s is still being created", c.Name)
+			}
+			continue
+		}
+		clusterConfigs = append(clusterConfigs, c)
+	}
+	if len(clusterConfigs) == 0 {
+		if clusterName != "" {
+			return nil, errors.Errorf("cluster %s not found in grid %s", clusterName, gridName)
+		}
+		return nil, errors.Errorf("grid %s has no clusters", gridName)
+	}
+
+	return clusterConfigs, nil
+}
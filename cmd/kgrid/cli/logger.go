@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
+	"github.com/spf13/viper"
+)
+
+// newLogger returns the logger for a grid, writing to the console in --log-format unless the spec's sinks say otherwise
+func newLogger(v *viper.Viper, loggerSpec types.LoggerSpec, gridName string, clusterName string) (logger.Logger, error) {
+	return logger.NewLogger(loggerSpec, logger.Options{
+		Format: v.GetString("log-format"),
+		Fields: logger.Fields{
+			Grid:    gridName,
+			Cluster: clusterName,
+		},
+	})
+}
+
+// gridSpecLogger returns the logger for a grid spec, which is set up by its first cluster
+func gridSpecLogger(v *viper.Viper, gridSpec *types.Grid) (logger.Logger, error) {
+	loggerSpec := types.LoggerSpec{}
+	if len(gridSpec.Spec.Clusters) > 0 {
+		loggerSpec = gridSpec.Spec.Clusters[0].Logger
+	}
+
+	// a grid with one cluster is how the operator runs each test
+	clusterName := ""
+	if len(gridSpec.Spec.Clusters) == 1 {
+		clusterName = gridSpec.Spec.Clusters[0].GetNameForLogging()
+	}
+
+	return newLogger(v, loggerSpec, gridSpec.Name, clusterName)
+}
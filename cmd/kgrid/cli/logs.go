@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/app"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/kubectl"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func LogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print the logs of a test, or of an app in a grid cluster",
+		Long: `Print the logs of a test pod run by the kgrid operator with --test,
+or of an app and its admin console in the clusters of a grid with --grid and --app.`,
+		SilenceErrors: true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			opts := kubectl.LogOptions{
+				Follow: v.GetBool("follow"),
+				Since:  v.GetDuration("since"),
+			}
+
+			testID := v.GetString("test")
+			gridName := v.GetString("grid")
+			switch {
+			case testID != "" && gridName != "":
+				return errors.New("--test and --grid can't be used together")
+			case testID != "":
+				return testLogs(cmd, v, testID, opts)
+			case gridName != "":
+				return appLogs(cmd, v, gridName, opts)
+			default:
+				return errors.New("--test or --grid is required")
+			}
+		},
+	}
+
+	cmd.Flags().String("test", "", "ID of the test to print the logs of")
+	cmd.Flags().StringP("grid", "g", "", "Name of the grid running the app")
+	cmd.Flags().StringP("cluster", "c", "", "Name of the cluster running the app, all clusters in the grid by default")
+	cmd.Flags().String("app", "", "Slug of the app to print the logs of")
+	cmd.Flags().BoolP("follow", "f", false, "Keep streaming the logs")
+	cmd.Flags().Duration("since", 0, "Only print logs newer than this, like 5s, 2m, or 3h")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+func testLogs(cmd *cobra.Command, v *viper.Viper, testID string, opts kubectl.LogOptions) error {
+	clients, err := getOperatorClients(v)
+	if err != nil {
+		return err
+	}
+
+	stream := kubectl.LogStream{
+		Clientset: clients.Kubernetes,
+		Namespace: clients.Namespace,
+		// the pod name is accepted too, since that's what kubectl shows
+		Pod: outcome.TestPodName(strings.TrimPrefix(testID, "test-")),
+	}
+	return kubectl.StreamLogs(cmd.Context(), []kubectl.LogStream{stream}, opts, cmd.OutOrStdout())
+}
+
+func appLogs(cmd *cobra.Command, v *viper.Viper, gridName string, opts kubectl.LogOptions) error {
+	appSlug := v.GetString("app")
+	if appSlug == "" {
+		return errors.New("--app is required with --grid")
+	}
+
+	store, err := getConfigStore(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to open config store")
+	}
+
+	clusterConfigs, err := selectClusters(store, gridName, v.GetString("cluster"))
+	if err != nil {
+		return err
+	}
+
+	streams := []kubectl.LogStream{}
+	for _, c := range clusterConfigs {
+		clients, err := cluster.GetClients(gridName, c)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get clients for cluster %s", c.Name)
+		}
+
+		pods, err := app.FindAppPods(cmd.Context(), clients, appSlug)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find pods in cluster %s", c.Name)
+		}
+
+		streams = append(streams, kubectl.PodLogStreams(clients.Kubernetes, pods, fmt.Sprintf("%s ", c.Name))...)
+	}
+	if len(streams) == 0 {
+		return errors.Errorf("no pods found for app %s", appSlug)
+	}
+
+	return kubectl.StreamLogs(cmd.Context(), streams, opts, cmd.OutOrStdout())
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"github.com/pkg/errors"
+	kgridclientset "github.com/replicatedhq/kgrid/pkg/client/kgridclientset/typed/kgrid/v1alpha1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// operatorClients are the clients for the cluster running the kgrid operator
+type operatorClients struct {
+	Kubernetes kubernetes.Interface
+	Kgrid      kgridclientset.KgridV1alpha1Interface
+	Namespace  string
+}
+
+// getOperatorClients is replaced in tests
+var getOperatorClients = func(v *viper.Viper) (*operatorClients, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = v.GetString("kubeconfig")
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: v.GetString("context"),
+	}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load kubeconfig for operator")
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+	kgridClient, err := kgridclientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kgrid client")
+	}
+
+	return &operatorClients{
+		Kubernetes: clientset,
+		Kgrid:      kgridClient,
+		Namespace:  v.GetString("namespace"),
+	}, nil
+}
+
+func addOperatorFlags(cmd *cobra.Command) {
+	cmd.Flags().String("kubeconfig", "", "Path to the kubeconfig for the cluster running the kgrid operator")
+	cmd.Flags().String("context", "", "Kubeconfig context for the cluster running the kgrid operator")
+	cmd.Flags().StringP("namespace", "n", "kgrid-system", "Namespace the kgrid operator runs tests in")
+}
+
+func addWatchFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolP("watch", "w", false, "Keep printing updates until every test has finished")
+}
@@ -0,0 +1,312 @@
+//go:build !windows
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	kgridv1alpha1 "github.com/replicatedhq/kgrid/apis/kgrid/v1alpha1"
+	kgridfake "github.com/replicatedhq/kgrid/pkg/client/kgridclientset/fake"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/app"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/health"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/kubectl"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://%s
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`
+
+// fakeKubectl prints its args, so the golden files show what kgrid passed to kubectl
+const fakeKubectl = `#!/bin/sh
+echo "kubectl $@"
+`
+
+func Test_OutputGolden(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	configFile := filepath.Join(tmpDir, "config")
+	err = grid.NewFileStore(configFile).Save(&types.GridsConfig{
+		GridConfigs: []*types.GridConfig{
+			{
+				Name: "test",
+				ClusterConfigs: []*types.ClusterConfig{
+					{
+						Name:        "grid-abc",
+						Provider:    "aws",
+						Region:      "us-east-1",
+						Version:     "1.21",
+						Description: "new cluster",
+						Kubeconfig:  fmt.Sprintf(testKubeconfig, "grid-abc"),
+						AccessKeyID: &types.ValueOrValueFrom{
+							ValueFrom: &types.ValueFrom{OSEnv: "AWS_ACCESS_KEY_ID"},
+						},
+						SecretAccessKey: &types.ValueOrValueFrom{Value: "secret"},
+						NodeGroupName:   "grid-abc",
+						CreatedAt:       &metav1.Time{Time: time.Date(2022, 7, 29, 9, 30, 0, 0, time.UTC)},
+					},
+					{
+						Name:       "shared",
+						Provider:   "aws",
+						IsExisting: true,
+						Region:     "us-west-2",
+						Kubeconfig: fmt.Sprintf(testKubeconfig, "shared"),
+					},
+					{
+						Name:     "grid-def",
+						Provider: "aws",
+						Region:   "us-east-1",
+						Creating: true,
+						CompletedSteps: []types.ClusterCreateStep{
+							types.ClusterCreateStepVPC,
+						},
+					},
+				},
+			},
+			{
+				Name: "empty",
+			},
+		},
+	})
+	req.NoError(err)
+
+	kubectlPath := filepath.Join(tmpDir, "kubectl")
+	req.NoError(ioutil.WriteFile(kubectlPath, []byte(fakeKubectl), 0755))
+	defer func(binary string) { kubectl.Binary = binary }(kubectl.Binary)
+	kubectl.Binary = kubectlPath
+
+	defer func(c func(context.Context, string, []*types.ClusterConfig, health.Options) []*health.ClusterHealth) {
+		checkHealth = c
+	}(checkHealth)
+	checkHealth = fakeCheckHealth
+	defer func(n func() time.Time) { now = n }(now)
+	now = func() time.Time { return testNow }
+
+	defer func(g func(v *viper.Viper) (*operatorClients, error)) { getOperatorClients = g }(getOperatorClients)
+	getOperatorClients = fakeOperatorClients
+
+	defer func(g func(string, *types.ClusterConfig) (*cluster.Clients, error)) { getClusterClients = g }(getClusterClients)
+	getClusterClients = fakeClusterClients
+
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{golden: "get-grids", args: []string{"get", "grids"}},
+		{golden: "get-grids-wide", args: []string{"get", "grids", "-o", "wide"}},
+		{golden: "get-grids-json", args: []string{"get", "grids", "-o", "json"}},
+		{golden: "get-grids-yaml", args: []string{"get", "grids", "-o", "yaml"}},
+		{golden: "get-grids-template", args: []string{"get", "grids", "-o", `go-template={{range .}}{{.name}}{{"\n"}}{{end}}`}},
+		{golden: "describe-grid", args: []string{"describe", "grid", "test"}},
+		{golden: "describe-grid-wide", args: []string{"describe", "grid", "test", "-o", "wide"}},
+		{golden: "describe-grid-json", args: []string{"describe", "grid", "test", "-o", "json"}},
+		{golden: "describe-grid-yaml", args: []string{"describe", "grid", "test", "-o", "yaml"}},
+		{golden: "get-clusters", args: []string{"get", "clusters", "--grid", "test"}},
+		{golden: "get-clusters-wide", args: []string{"get", "clusters", "--grid", "test", "-o", "wide"}},
+		{golden: "get-clusters-json", args: []string{"get", "clusters", "--grid", "test", "--cluster", "grid-abc", "-o", "json"}},
+		{golden: "get-clusters-unknown-cluster", args: []string{"get", "clusters", "--grid", "test", "--cluster", "missing"}},
+		{golden: "get-clusters-creating-cluster", args: []string{"get", "clusters", "--grid", "test", "--cluster", "grid-def"}},
+		{golden: "get-namespaces", args: []string{"get", "namespaces", "--grid", "test"}},
+		{golden: "get-namespaces-json", args: []string{"get", "namespaces", "--grid", "test", "-o", "json"}},
+		{golden: "get-namespaces-creating-cluster", args: []string{"get", "namespaces", "--grid", "test", "--cluster", "grid-def"}},
+		{golden: "kubeconfig", args: []string{"kubeconfig", "--grid", "test", "--cluster", "shared"}},
+		{golden: "kubectl", args: []string{"kubectl", "--grid", "test", "--cluster", "shared", "--", "get", "pods"}},
+		{golden: "exec-missing-binary", args: []string{"exec", "--grid", "test", "--", "kgrid-missing-binary"}},
+		{golden: "get-outcomes", args: []string{"get", "outcomes"}},
+		{golden: "get-outcomes-wide", args: []string{"get", "outcomes", "-o", "wide"}},
+		{golden: "get-outcomes-run", args: []string{"get", "outcomes", "run-1"}},
+		{golden: "describe-outcome", args: []string{"describe", "outcome", "run-2"}},
+		{golden: "describe-outcome-json", args: []string{"describe", "outcome", "run-2", "-o", "json"}},
+		{golden: "describe-outcome-not-found", args: []string{"describe", "outcome", "missing"}},
+		{golden: "get-tests", args: []string{"get", "tests"}},
+		{golden: "get-tests-app-wide", args: []string{"get", "tests", "--app", "sentry", "-o", "wide"}},
+		{golden: "logs-test", args: []string{"logs", "--test", "a2"}},
+		{golden: "logs-missing-flags", args: []string{"logs"}},
+		{golden: "get-grids-unknown-format", args: []string{"get", "grids", "-o", "xml"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			req := require.New(t)
+
+			// ahead of the args, so it isn't passed through after a --
+			actual := runCommand(append([]string{"--config-file", configFile}, test.args...)...)
+
+			goldenFile := filepath.Join("testdata", test.golden+".golden")
+			if *update {
+				req.NoError(ioutil.WriteFile(goldenFile, []byte(actual), 0644))
+			}
+
+			expected, err := ioutil.ReadFile(goldenFile)
+			req.NoError(err)
+			assert.Equal(t, string(expected), actual)
+
+			if strings.HasSuffix(test.golden, "-json") {
+				assert.True(t, json.Valid([]byte(actual)), "output isn't valid json")
+			}
+		})
+	}
+}
+
+var testNow = time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeClusterClients gives each cluster a default namespace, and grid-abc an app namespace too
+func fakeClusterClients(gridName string, c *types.ClusterConfig) (*cluster.Clients, error) {
+	namespace := func(name string, createdAt time.Time) runtime.Object {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Time{Time: createdAt}},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		}
+	}
+
+	objects := []runtime.Object{namespace("default", testNow.Add(-72*time.Hour))}
+	if c.Name == "grid-abc" {
+		objects = append(objects, namespace("app", testNow.Add(-2*time.Hour)))
+	}
+	return &cluster.Clients{Kubernetes: fake.NewSimpleClientset(objects...)}, nil
+}
+
+// fakeCheckHealth reports grid-abc as ready with an app, and every other cluster that isn't being created as unreachable
+func fakeCheckHealth(ctx context.Context, gridName string, clusterConfigs []*types.ClusterConfig, opts health.Options) []*health.ClusterHealth {
+	results := []*health.ClusterHealth{}
+	for _, c := range clusterConfigs {
+		h := &health.ClusterHealth{
+			Name:       c.Name,
+			Provider:   c.Provider,
+			Region:     c.Region,
+			IsExisting: c.IsExisting,
+			Creating:   c.Creating,
+			CreatedAt:  c.CreatedAt,
+		}
+		switch {
+		case c.Creating:
+		case c.Name == "grid-abc":
+			h.Reachable = true
+			h.ServerVersion = "v1.21.14-eks-18ef993"
+			h.Nodes = 2
+			h.ReadyNodes = 2
+			h.Apps = []app.DeployedApp{{Namespace: "app", Slug: "sentry", State: "ready"}}
+		default:
+			h.Error = fmt.Sprintf("timed out after %s", opts.Timeout)
+		}
+		results = append(results, h)
+	}
+	return results
+}
+
+// fakeOperatorClients has a finished run and a run that's still going, with pods for the tests in the second run
+func fakeOperatorClients(v *viper.Viper) (*operatorClients, error) {
+	at := func(minutes int) *metav1.Time {
+		return &metav1.Time{Time: testNow.Add(time.Duration(minutes) * time.Minute)}
+	}
+	testPod := func(id string, app string, clusterName string, phase corev1.PodPhase, startedAt *metav1.Time, finishedAt *metav1.Time) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-" + id,
+				Namespace: "kgrid-system",
+				Labels:    map[string]string{outcome.TestPodLabelKey: id},
+				Annotations: map[string]string{
+					outcome.RunAnnotationKey:     "run-2",
+					outcome.AppAnnotationKey:     app,
+					outcome.ClusterAnnotationKey: clusterName,
+					outcome.VersionAnnotationKey: "1.1.0",
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase:     phase,
+				StartTime: startedAt,
+			},
+		}
+		if finishedAt != nil {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: "grid", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: *finishedAt}}},
+			}
+		}
+		return pod
+	}
+
+	outcomes := []runtime.Object{
+		&kgridv1alpha1.Outcome{
+			ObjectMeta: metav1.ObjectMeta{Name: "run-1", Namespace: "kgrid-system", CreationTimestamp: *at(-24 * 60)},
+			Tests: []kgridv1alpha1.Test{
+				{ID: "a1", Result: kgridv1alpha1.TestResultPass, App: "sentry", Cluster: "eks-1-21", Version: "1.0.0", StartedAt: at(-24 * 60), FinishedAt: at(-23 * 60)},
+				{ID: "b1", Result: kgridv1alpha1.TestResultFail, App: "sentry", Cluster: "eks-1-22", Version: "1.0.0", StartedAt: at(-24 * 60), FinishedAt: at(-24*60 + 12)},
+			},
+		},
+		&kgridv1alpha1.Outcome{
+			ObjectMeta: metav1.ObjectMeta{Name: "run-2", Namespace: "kgrid-system", CreationTimestamp: *at(-30)},
+			Tests: []kgridv1alpha1.Test{
+				{ID: "a2", Result: kgridv1alpha1.TestResultPass, App: "sentry", Cluster: "eks-1-21", Version: "1.1.0", StartedAt: at(-30), FinishedAt: at(-10)},
+				{ID: "b2", Result: kgridv1alpha1.TestResultPending, App: "sentry", Cluster: "eks-1-22", Version: "1.1.0", StartedAt: at(-30)},
+				{ID: "c2", Result: kgridv1alpha1.TestResultPending, App: "ghost", Cluster: "eks-1-21", Version: "1.1.0"},
+			},
+		},
+	}
+	pods := []runtime.Object{
+		testPod("a2", "sentry", "eks-1-21", corev1.PodSucceeded, at(-30), at(-10)),
+		testPod("b2", "sentry", "eks-1-22", corev1.PodRunning, at(-30), nil),
+		testPod("c2", "ghost", "eks-1-21", corev1.PodPending, nil, nil),
+	}
+
+	return &operatorClients{
+		Kubernetes: fake.NewSimpleClientset(pods...),
+		Kgrid:      kgridfake.NewSimpleClientset(outcomes...).KgridV1alpha1(),
+		Namespace:  v.GetString("namespace"),
+	}, nil
+}
+
+// runCommand runs the kgrid command line, returning its output followed by any error
+func runCommand(args ...string) string {
+	var out bytes.Buffer
+
+	cmd := RootCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		fmt.Fprintf(&out, "Error: %v\n", err)
+	}
+
+	return out.String()
+}
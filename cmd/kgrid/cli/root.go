@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -40,6 +43,11 @@ func RootCmd() *cobra.Command {
 	KubernetesConfigFlags.AddFlags(cmd.Flags())
 
 	cmd.PersistentFlags().String("config-file", filepath.Join(homeDir(), ".grid", "config"), "Path to the grid config file to store current grids")
+	cmd.PersistentFlags().String("store", "file", "Where to store the grid config: file, encrypted (passphrase from GRID_CONFIG_PASSPHRASE, or --identity-file), or k8s (secrets in --store-namespace)")
+	cmd.PersistentFlags().String("store-namespace", "kgrid-system", "Namespace holding grid config secrets when --store=k8s")
+	cmd.PersistentFlags().String("store-kubeconfig", "", "Path to the kubeconfig for the cluster holding grid config secrets when --store=k8s")
+	cmd.PersistentFlags().String("identity-file", "", "Path to an age identity file used to encrypt the grid config when --store=encrypted")
+	cmd.PersistentFlags().String("log-format", "auto", "Log format: terminal, json, or auto to use json when stdout isn't a terminal")
 
 	cmd.AddCommand(VersionCmd())
 	cmd.AddCommand(CreateCmd())
@@ -47,6 +55,10 @@ func RootCmd() *cobra.Command {
 	cmd.AddCommand(DescribeCmd())
 	cmd.AddCommand(DeployCmd())
 	cmd.AddCommand(DeleteCmd())
+	cmd.AddCommand(KubeconfigCmd())
+	cmd.AddCommand(KubectlCmd())
+	cmd.AddCommand(ExecCmd())
+	cmd.AddCommand(LogsCmd())
 	cmd.AddCommand(RunCmd())
 
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -54,12 +66,33 @@ func RootCmd() *cobra.Command {
 }
 
 func InitAndExecute() {
-	if err := RootCmd().Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// after the first signal, restore default handling so a second one exits immediately
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := RootCmd().ExecuteContext(ctx); err != nil {
+		if exitErr, ok := err.(*exitCodeError); ok {
+			os.Exit(exitErr.code)
+		}
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// exitCodeError exits with code, for commands that have already reported what went wrong
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
 func initConfig() {
 	viper.SetEnvPrefix("GRID")
 	viper.AutomaticEnv()
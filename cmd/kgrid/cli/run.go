@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"io/ioutil"
 
 	"github.com/pkg/errors"
@@ -28,28 +29,19 @@ func RunCmd() *cobra.Command {
 				return
 			}
 
-			data, err := ioutil.ReadFile(v.GetString("from-yaml"))
+			store, err := getConfigStore(v)
 			if err != nil {
-				testError = errors.Wrap(err, "failed to read from-yaml file")
+				testError = errors.Wrap(err, "failed to open config store")
 				return
 			}
 
-			gridSpec := types.Grid{}
-			if err := yaml.Unmarshal(data, &gridSpec); err != nil {
-				testError = errors.Wrapf(err, "failed to unmarshal %s", v.GetString("from-yaml"))
-				return
-			}
-
-			if len(gridSpec.Spec.Clusters) == 0 {
-				testError = errors.New("no clusters defined in spec")
+			gridSpec, err := loadGridSpec(v, store)
+			if err != nil {
+				testError = err
 				return
 			}
 
-			if v.GetString("name") != "" {
-				gridSpec.Name = v.GetString("name")
-			}
-
-			data, err = ioutil.ReadFile(v.GetString("app"))
+			data, err := ioutil.ReadFile(v.GetString("app"))
 			if err != nil {
 				testError = errors.Wrap(err, "failed to read app spec file")
 				return
@@ -61,27 +53,37 @@ func RunCmd() *cobra.Command {
 				return
 			}
 
-			log := logger.NewLogger(gridSpec.Spec.Clusters[0].Logger)
+			log, err := gridSpecLogger(v, gridSpec)
+			if err != nil {
+				testError = err
+				return
+			}
 			log.StartThread("Testing app %s", getAppDisplayName(*application))
 			defer func() {
 				resultMark := ":white_check_mark:"
 				if testError != nil {
 					resultMark = ":x:"
 				}
+				log.TestResult(getAppDisplayName(*application), testError)
 				log.FinishThread("%s Testing app %s", resultMark, getAppDisplayName(*application))
 			}()
 
-			if err := grid.Create(v.GetString("config-file"), &gridSpec, log); err != nil {
+			// failed clusters are never kept, since nothing would resume them
+			if err := grid.Create(cmd.Context(), store, gridSpec, grid.CreateOptions{}, log); err != nil {
 				testError = errors.Wrap(err, "failed to create cluster")
+				// create only rolls back the clusters that didn't finish, the rest of the grid is deleted here
+				if err := deleteGridIfExists(store, gridSpec.Name, log); err != nil {
+					log.Error(errors.Wrap(err, "failed to delete grid"))
+				}
 				return
 			}
 
-			if err := deployApp(v.GetString("config-file"), gridSpec.Name, v.GetString("app"), log); err != nil {
+			if err := deployApp(cmd.Context(), store, gridSpec.Name, v.GetString("app"), waitSpecFromFlags(v), log); err != nil {
 				testError = errors.Wrap(err, "failed to deploy app")
 				// clean up cluster
 			}
 
-			if err := grid.Delete(v.GetString("config-file"), &gridSpec, log); err != nil {
+			if err := deleteGridIfExists(store, gridSpec.Name, log); err != nil {
 				// TODO: maybe this shouldn't fail the test
 				testError = errors.Wrap(err, "failed to delete cluster")
 				return
@@ -94,7 +96,26 @@ func RunCmd() *cobra.Command {
 	cmd.Flags().StringP("name", "n", "", "Name of the grid, overriding the name in the yaml metadata.name field")
 	cmd.Flags().String("from-yaml", "", "Path to YAML manifest describing the grid to create")
 	cmd.Flags().String("like", "", "Name of an existing grid to clone, into a new grid")
+	cmd.Flags().String("region", "", "Region for new clusters when cloning a grid with --like, overriding the cloned region")
+	cmd.Flags().String("version", "", "Kubernetes version for new clusters when cloning a grid with --like, overriding the cloned version")
 	cmd.Flags().String("app", "", "Path to YAML manifest describing the application to deploy after grid is created")
+	addWaitFlags(cmd)
 
 	return cmd
 }
+
+// deleteGridIfExists deletes the grid with a fresh context, so an interrupted run still cleans up
+func deleteGridIfExists(store grid.ConfigStore, gridName string, log logger.Logger) error {
+	gridConfigs, err := grid.List(store)
+	if err != nil {
+		return errors.Wrap(err, "failed to list grids")
+	}
+
+	for _, g := range gridConfigs {
+		if g.Name == gridName {
+			return grid.Delete(context.Background(), store, gridName, "", log)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// getConfigStore returns the grid config store selected by the --store flag
+func getConfigStore(v *viper.Viper) (grid.ConfigStore, error) {
+	configFile := v.GetString("config-file")
+
+	switch v.GetString("store") {
+	case "", "file":
+		return grid.NewFileStore(configFile), nil
+	case "encrypted":
+		if identityFile := v.GetString("identity-file"); identityFile != "" {
+			return grid.NewIdentityFileStore(configFile, identityFile)
+		}
+		// read from the environment only, so the passphrase isn't visible in the process list
+		passphrase := v.GetString("config-passphrase")
+		if passphrase == "" {
+			return nil, errors.New("encrypted store requires --identity-file or GRID_CONFIG_PASSPHRASE")
+		}
+		return grid.NewPassphraseStore(configFile, passphrase)
+	case "k8s":
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = v.GetString("store-kubeconfig")
+		restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load kubeconfig for k8s store")
+		}
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create clientset for k8s store")
+		}
+		return grid.NewSecretStore(clientset, v.GetString("store-namespace")), nil
+	default:
+		return nil, errors.Errorf("unknown store %q", v.GetString("store"))
+	}
+}
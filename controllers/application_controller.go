@@ -38,9 +38,12 @@ import (
 	kgridclientset "github.com/replicatedhq/kgrid/pkg/client/kgridclientset/typed/kgrid/v1alpha1"
 	"github.com/replicatedhq/kgrid/pkg/config"
 	gridtypes "github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
 )
 
-const TestPodLabelKey = "kgrid.replicated.com/test"
+// testRunnerServiceAccountName can read and write the grid config secrets in the operator namespace,
+// so grids created by test pods are in the same store as `kgrid --store=k8s`
+const testRunnerServiceAccountName = "kgrid-test-runner"
 
 // ApplicationReconciler reconciles a Application object
 type ApplicationReconciler struct {
@@ -116,7 +119,7 @@ func getTestID(runID string, cluster string, version string, channelID string, c
 }
 
 func getPodName(testID string) string {
-	return fmt.Sprintf("test-%s", testID)
+	return outcome.TestPodName(testID)
 }
 
 func createAppTests(ctx context.Context, namespace string, app *kgridv1alpha1.Application, version string, runID string, logger logr.Logger) ([]kgridv1alpha1.Test, error) {
@@ -165,9 +168,15 @@ func createAppTests(ctx context.Context, namespace string, app *kgridv1alpha1.Ap
 				testID := getTestID(runID, gridCluster.Name, version, channelID, channelSequence)
 				pod, err := clientset.CoreV1().Pods(app.Namespace).Get(ctx, getPodName(testID), metav1.GetOptions{})
 				if err == nil {
+					startedAt, finishedAt := outcome.TestTimesFromPod(pod)
 					tests = append(tests, kgridv1alpha1.Test{
-						ID:     testID,
-						Result: getTestResultFromPod(pod),
+						ID:         testID,
+						Result:     outcome.TestResultFromPod(pod),
+						App:        app.Name,
+						Cluster:    gridCluster.Name,
+						Version:    version,
+						StartedAt:  startedAt,
+						FinishedAt: finishedAt,
 					})
 					continue
 				}
@@ -185,14 +194,17 @@ func createAppTests(ctx context.Context, namespace string, app *kgridv1alpha1.Ap
 					return nil, errors.Wrap(err, "failed to create test config")
 				}
 
-				podSpec := getTestPodSpec(runID, testID, &gridCluster, app)
+				podSpec := getTestPodSpec(runID, testID, &gridCluster, app, version)
 				_, err = clientset.CoreV1().Pods(app.Namespace).Create(ctx, podSpec, metav1.CreateOptions{})
 				if err != nil {
 					return nil, errors.Wrap(err, "failed to create test")
 				}
 				tests = append(tests, kgridv1alpha1.Test{
-					ID:     testID,
-					Result: kgridv1alpha1.TestResultPending,
+					ID:      testID,
+					Result:  kgridv1alpha1.TestResultPending,
+					App:     app.Name,
+					Cluster: gridCluster.Name,
+					Version: version,
 				})
 			}
 		}
@@ -206,20 +218,27 @@ func createAppTests(ctx context.Context, namespace string, app *kgridv1alpha1.Ap
 	return tests, nil
 }
 
-func getTestPodSpec(runID string, testID string, gridCluster *kgridv1alpha1.Cluster, app *kgridv1alpha1.Application) *corev1.Pod {
+func getTestPodSpec(runID string, testID string, gridCluster *kgridv1alpha1.Cluster, app *kgridv1alpha1.Application, version string) *corev1.Pod {
 	trueVal := true
 	podSpec := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: getPodName(testID),
 			Labels: map[string]string{
-				TestPodLabelKey: testID,
+				outcome.TestPodLabelKey: testID,
+			},
+			Annotations: map[string]string{
+				outcome.RunAnnotationKey:     runID,
+				outcome.AppAnnotationKey:     app.Name,
+				outcome.ClusterAnnotationKey: gridCluster.Name,
+				outcome.VersionAnnotationKey: version,
 			},
 		},
 		Spec: corev1.PodSpec{
 			Affinity: &corev1.Affinity{
 				NodeAffinity: defaultKgridNodeAffinity(),
 			},
-			RestartPolicy: corev1.RestartPolicyNever,
+			RestartPolicy:      corev1.RestartPolicyNever,
+			ServiceAccountName: testRunnerServiceAccountName,
 			Containers: []corev1.Container{
 				{
 					Image:           fmt.Sprintf("%s:%s", kgridImageName(), buildversion.ImageTag()),
@@ -232,6 +251,13 @@ func getTestPodSpec(runID string, testID string, gridCluster *kgridv1alpha1.Clus
 						"/kgrid-specs/grid.yaml",
 						"--app",
 						"/kgrid-specs/app.yaml",
+						// tests of the same grid cluster run at the same time, so each needs its own grid in the shared store
+						"--name",
+						fmt.Sprintf("%s-%s", gridCluster.Name, testID),
+						"--store",
+						"k8s",
+						"--store-namespace",
+						app.Namespace,
 					},
 					Env: []corev1.EnvVar{
 						{
@@ -37,6 +37,7 @@ import (
 	kgridv1alpha1 "github.com/replicatedhq/kgrid/apis/kgrid/v1alpha1"
 	kgridclientset "github.com/replicatedhq/kgrid/pkg/client/kgridclientset/typed/kgrid/v1alpha1"
 	"github.com/replicatedhq/kgrid/pkg/config"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
 )
 
 // OutcomeReconciler reconciles a Outcome object
@@ -81,7 +82,7 @@ func (r *OutcomeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	for _, test := range instance.Tests {
 		testIDs = append(testIDs, test.ID)
 	}
-	selector := fmt.Sprintf("%s in (%s)", TestPodLabelKey, strings.Join(testIDs, ", "))
+	selector := fmt.Sprintf("%s in (%s)", outcome.TestPodLabelKey, strings.Join(testIDs, ", "))
 	pods, err := clientset.CoreV1().Pods(instance.Namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: selector,
 	})
@@ -89,17 +90,16 @@ func (r *OutcomeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, errors.Wrap(err, "failed to list test pods")
 	}
 
-	var testIDsToResult = map[string]kgridv1alpha1.TestResult{}
-	for _, pod := range pods.Items {
-		podTestID := pod.Labels[TestPodLabelKey]
-		testIDsToResult[podTestID] = getTestResultFromPod(&pod)
+	var testIDsToPod = map[string]*corev1.Pod{}
+	for i := range pods.Items {
+		testIDsToPod[pods.Items[i].Labels[outcome.TestPodLabelKey]] = &pods.Items[i]
 	}
 
 	finalized := true
 	updated := false
 
 	for i, test := range instance.Tests {
-		result, ok := testIDsToResult[test.ID]
+		pod, ok := testIDsToPod[test.ID]
 		if !ok {
 			// The test pod is not in the cluster. Unless we already have a final Pass/Fail result
 			// for it then the final state will be Unknown.
@@ -111,6 +111,7 @@ func (r *OutcomeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			continue
 		}
 
+		result := outcome.TestResultFromPod(pod)
 		if result == kgridv1alpha1.TestResultPending {
 			finalized = false
 		}
@@ -118,6 +119,13 @@ func (r *OutcomeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			instance.Tests[i].Result = result
 			updated = true
 		}
+
+		startedAt, finishedAt := outcome.TestTimesFromPod(pod)
+		if !timesEqual(test.StartedAt, startedAt) || !timesEqual(test.FinishedAt, finishedAt) {
+			instance.Tests[i].StartedAt = startedAt
+			instance.Tests[i].FinishedAt = finishedAt
+			updated = true
+		}
 	}
 
 	if updated {
@@ -201,17 +209,9 @@ func updateOutcome(ctx context.Context, outcome *kgridv1alpha1.Outcome) (*kgridv
 	return outcome, nil
 }
 
-func getTestResultFromPod(pod *corev1.Pod) kgridv1alpha1.TestResult {
-	switch pod.Status.Phase {
-	case corev1.PodSucceeded:
-		return kgridv1alpha1.TestResultPass
-	case corev1.PodPending, corev1.PodRunning:
-		return kgridv1alpha1.TestResultPending
-	case corev1.PodFailed:
-		return kgridv1alpha1.TestResultFail
-	case corev1.PodUnknown:
-		return kgridv1alpha1.TestResultUnknown
+func timesEqual(a *metav1.Time, b *metav1.Time) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
-
-	return kgridv1alpha1.TestResultUnknown
+	return a.Equal(b)
 }
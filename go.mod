@@ -3,6 +3,7 @@ module github.com/replicatedhq/kgrid
 go 1.19
 
 require (
+	filippo.io/age v1.0.0
 	github.com/aws/aws-sdk-go v1.44.102
 	github.com/aws/aws-sdk-go-v2 v0.31.0
 	github.com/aws/aws-sdk-go-v2/config v0.4.0
@@ -13,7 +14,6 @@ require (
 	github.com/aws/smithy-go v0.5.0
 	github.com/fatih/color v1.13.0
 	github.com/go-logr/logr v1.2.3
-	github.com/gosimple/slug v1.9.0
 	github.com/mattn/go-isatty v0.0.16
 	github.com/onsi/ginkgo v1.16.5
 	github.com/onsi/gomega v1.19.0
@@ -25,6 +25,7 @@ require (
 	github.com/spf13/viper v1.13.1-0.20220927210724-f1d2c470bfa7
 	github.com/stretchr/testify v1.8.0
 	github.com/tj/go-spin v1.1.0
+	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8
 	k8s.io/api v0.25.2
 	k8s.io/apiextensions-apiserver v0.25.0
 	k8s.io/apimachinery v0.25.2
@@ -146,7 +147,6 @@ require (
 	github.com/prometheus/client_model v0.2.0 // indirect
 	github.com/prometheus/common v0.37.0 // indirect
 	github.com/prometheus/procfs v0.8.0 // indirect
-	github.com/rainycape/unidecode v0.0.0-20150907023854-cb7f23ec59be // indirect
 	github.com/replicatedhq/kurl v0.0.0-20210414162418-8d6211901244 // indirect
 	github.com/replicatedhq/troubleshoot v0.45.0 // indirect
 	github.com/replicatedhq/yaml/v3 v3.0.0-beta5-replicatedhq // indirect
@@ -179,7 +179,6 @@ require (
 	golang.org/x/net v0.0.0-20221002022538-bcab6841153b // indirect
 	golang.org/x/oauth2 v0.0.0-20220909003341-f21342109be1 // indirect
 	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4 // indirect
-	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8 // indirect
 	golang.org/x/term v0.0.0-20220526004731-065cf7ba2467 // indirect
 	golang.org/x/text v0.3.8 // indirect
 	golang.org/x/time v0.0.0-20220722155302-e5dcc9cfc0b9 // indirect
@@ -35,9 +35,9 @@ type FakeGrids struct {
 	ns   string
 }
 
-var gridsResource = schema.GroupVersionResource{Group: "kgrid", Version: "v1alpha1", Resource: "grids"}
+var gridsResource = schema.GroupVersionResource{Group: "kgrid.replicated.com", Version: "v1alpha1", Resource: "grids"}
 
-var gridsKind = schema.GroupVersionKind{Group: "kgrid", Version: "v1alpha1", Kind: "Grid"}
+var gridsKind = schema.GroupVersionKind{Group: "kgrid.replicated.com", Version: "v1alpha1", Kind: "Grid"}
 
 // Get takes name of the grid, and returns the corresponding grid object, and an error if there is any.
 func (c *FakeGrids) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.Grid, err error) {
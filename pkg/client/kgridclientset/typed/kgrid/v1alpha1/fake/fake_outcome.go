@@ -35,9 +35,9 @@ type FakeOutcomes struct {
 	ns   string
 }
 
-var outcomesResource = schema.GroupVersionResource{Group: "kgrid", Version: "v1alpha1", Resource: "outcomes"}
+var outcomesResource = schema.GroupVersionResource{Group: "kgrid.replicated.com", Version: "v1alpha1", Resource: "outcomes"}
 
-var outcomesKind = schema.GroupVersionKind{Group: "kgrid", Version: "v1alpha1", Kind: "Outcome"}
+var outcomesKind = schema.GroupVersionKind{Group: "kgrid.replicated.com", Version: "v1alpha1", Kind: "Outcome"}
 
 // Get takes name of the outcome, and returns the corresponding outcome object, and an error if there is any.
 func (c *FakeOutcomes) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.Outcome, err error) {
@@ -35,9 +35,9 @@ type FakeVersions struct {
 	ns   string
 }
 
-var versionsResource = schema.GroupVersionResource{Group: "kgrid", Version: "v1alpha1", Resource: "versions"}
+var versionsResource = schema.GroupVersionResource{Group: "kgrid.replicated.com", Version: "v1alpha1", Resource: "versions"}
 
-var versionsKind = schema.GroupVersionKind{Group: "kgrid", Version: "v1alpha1", Kind: "Version"}
+var versionsKind = schema.GroupVersionKind{Group: "kgrid.replicated.com", Version: "v1alpha1", Kind: "Version"}
 
 // Get takes name of the version, and returns the corresponding version object, and an error if there is any.
 func (c *FakeVersions) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.Version, err error) {
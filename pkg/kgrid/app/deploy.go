@@ -1,14 +1,16 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
-	"reflect"
-	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
 )
 
 type DeployStatus string
@@ -19,127 +21,159 @@ const (
 	DeploySucceeded  DeployStatus = "succeeded"
 )
 
-func Deploy(g *types.GridConfig, a *types.Application, log logger.Logger) (finalError error) {
+// deployConcurrency is the max number of clusters deployed to at the same time
+const deployConcurrency = 8
+
+func Deploy(ctx context.Context, g *types.GridConfig, a *types.Application, log logger.Logger) error {
 	if len(g.ClusterConfigs) == 0 {
 		return errors.New("no clusters configured")
 	}
 
-	deployStatuses := map[int]DeployStatus{}
-	deployChans := make([]chan string, len(g.ClusterConfigs))
-	for i := range g.ClusterConfigs {
-		deployStatuses[i] = DeployInProgress
-		deployChans[i] = make(chan string)
+	supportBundleOpts, err := getSupportBundleOptions(a.Spec)
+	if err != nil {
+		return errors.Wrap(err, "invalid support bundle spec")
 	}
 
-	finished := make(chan bool)
-	go func() {
-		cases := make([]reflect.SelectCase, len(deployChans))
-		for i, ch := range deployChans {
-			cases[i] = reflect.SelectCase{
-				Dir:  reflect.SelectRecv,
-				Chan: reflect.ValueOf(ch),
-			}
+	// the binary is shared by all clusters, so only fetch it once
+	var pathToKOTSBinary string
+	if a.Spec.KOTSApplicationSpec != nil {
+		p, err := downloadKOTSBinary(ctx, a.Spec.KOTSApplicationSpec.Version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get kots %s binary", a.Spec.KOTSApplicationSpec.Version)
 		}
+		pathToKOTSBinary = p
+	}
 
-		wg := sync.WaitGroup{}
-
-		for {
-			i, deployErr, ok := reflect.Select(cases)
-			if ok {
-				if deployErr.String() != "" {
-					finalError = errors.New("application failed to deploy")
-					deployStatuses[i] = DeployFailed
-
-					log.Info("deploy to cluster %s failed with error: %s\n", g.ClusterConfigs[i].Name, deployErr.String())
-					log.Info("generating support bundle for cluster %s\n", g.ClusterConfigs[i].Name)
-
-					wg.Add(1)
-					go func(clusterConfig *types.ClusterConfig, log logger.Logger) {
-						defer wg.Done()
-						path, err := generateSupportBundle(clusterConfig, log)
-						if err != nil {
-							log.Info("failed to generate a support bundle for cluster %s, %v", clusterConfig.Name, err)
-							return
-						}
-						if err := uploadSupportBundle(path, log); err != nil {
-							log.Info("failed to upload support bundle for cluster %s: %v", clusterConfig.Name, err)
-							return
-						}
-					}(g.ClusterConfigs[i], log)
-				} else {
-					deployStatuses[i] = DeploySucceeded
-				}
+	opts := getWaitOptions(a.Spec.Wait)
+
+	tasks := []parallel.Task[DeployStatus]{}
+	for _, c := range g.ClusterConfigs {
+		c := c
+		tasks = append(tasks, func(ctx context.Context) (DeployStatus, error) {
+			clusterLog := log.ForCluster(c.Name)
+			if c.Creating {
+				err := errors.New("cluster create did not finish, run create --resume first")
+				clusterLog.Error(err)
+				return DeployFailed, err
+			}
+
+			clients, err := cluster.GetClients(g.Name, c)
+			if err != nil {
+				err = errors.Wrap(err, "failed to get cluster clients")
+				clusterLog.Error(err)
+				return DeployFailed, err
 			}
 
-			allCompleted := true
-			for _, v := range deployStatuses {
-				if v != DeploySucceeded && v != DeployFailed {
-					allCompleted = false
+			deployErr := deployToCluster(ctx, clients, a, pathToKOTSBinary, opts, clusterLog)
+			if deployErr != nil {
+				clusterLog.Error(deployErr)
+			}
+
+			if supportBundleOpts.shouldCollect(deployErr) {
+				clusterLog.Info("Generating support bundle")
+				appSpec := func() (string, error) {
+					appSlug, err := getKOTSAppSlug(ctx, clients, a.Spec.KOTSApplicationSpec, pathToKOTSBinary)
+					if err != nil {
+						return "", errors.Wrap(err, "failed to get app slug")
+					}
+					return kotsAppSupportBundleSpec(getKOTSNamespace(a.Spec.KOTSApplicationSpec), appSlug), nil
 				}
+				collectSupportBundle(ctx, c, clients, supportBundleOpts, appSpec, clusterLog)
 			}
 
-			if allCompleted {
-				wg.Wait()
-				finished <- true
-				return
+			if deployErr != nil {
+				return DeployFailed, deployErr
 			}
+
+			return DeploySucceeded, nil
+		})
+	}
+
+	deployErrors := []error{}
+	for _, result := range parallel.Run(ctx, deployConcurrency, tasks) {
+		if result.Err != nil {
+			deployErrors = append(deployErrors, errors.Wrapf(result.Err, "deploy to cluster %s", g.ClusterConfigs[result.Index].Name))
 		}
-	}()
-
-	// deploy the app
-	for i, c := range g.ClusterConfigs {
-		if a.Spec.KOTSApplicationSpec != nil {
-			go func() {
-				pathToKOTSBinary, err := downloadKOTSBinary(a.Spec.KOTSApplicationSpec.Version)
-				if err != nil {
-					deployChans[i] <- errors.Wrapf(err, "failed to get kots %s binary", a.Spec.KOTSApplicationSpec.Version).Error()
-					return
-				}
+	}
 
-				err = deployKOTSApplication(c, a.Spec.KOTSApplicationSpec, pathToKOTSBinary, log)
-				if err != nil {
-					deployChans[i] <- err.Error()
-					return
-				}
+	if len(deployErrors) > 0 {
+		return errors.Wrap(&kerrors.MultiError{Errors: deployErrors}, "application failed to deploy")
+	}
 
-				waitUntil := time.Now().Add(5 * time.Minute)
-				var lastError error
-				for {
-					appStatus, err := getKOTSApplicationStatus(c, a.Spec.KOTSApplicationSpec, pathToKOTSBinary, log)
-					if err != nil {
-						lastError = err
-						continue
-					}
+	return nil
+}
 
-					statusString, _ := json.MarshalIndent(appStatus, "", "  ")
-					log.Info("```%s```", statusString)
-					if appStatus.AppStatus.State == "ready" {
-						lastError = nil
-						break
-					}
+func deployToCluster(ctx context.Context, clients *cluster.Clients, a *types.Application, pathToKOTSBinary string, opts waitOptions, log logger.Logger) error {
+	if a.Spec.KOTSApplicationSpec == nil {
+		return nil
+	}
 
-					if time.Now().After(waitUntil) {
-						if lastError != nil {
-							lastError = errors.Wrap(lastError, "timed out waiting for app ready status")
-						} else {
-							lastError = errors.New("timed out waiting for app ready status")
-						}
-						break
-					}
+	err := deployKOTSApplication(ctx, clients, a.Spec.KOTSApplicationSpec, pathToKOTSBinary, opts.installTimeout, log)
+	if err != nil {
+		return err
+	}
 
-					time.Sleep(10 * time.Second)
-				}
+	appSlug, err := getKOTSAppSlug(ctx, clients, a.Spec.KOTSApplicationSpec, pathToKOTSBinary)
+	if err != nil {
+		return errors.Wrap(err, "failed to get app slug")
+	}
 
-				if lastError != nil {
-					deployChans[i] <- lastError.Error()
-				} else {
-					deployChans[i] <- ""
-				}
-			}()
-		}
+	return waitForKOTSApplicationReady(ctx, clients, a.Spec.KOTSApplicationSpec, appSlug, pathToKOTSBinary, opts, log)
+}
+
+// collectSupportBundle generates a support bundle and links it from s3, or attaches it to the logs when there's no bucket.
+// Any failure is logged.
+func collectSupportBundle(ctx context.Context, c *types.ClusterConfig, clients *cluster.Clients, opts supportBundleOptions, appSpec func() (string, error), log logger.Logger) {
+	path, err := generateSupportBundle(ctx, clients, opts, appSpec, log)
+	if err != nil {
+		log.Info("Failed to generate a support bundle: %v", err)
+		return
+	}
+
+	url, err := uploadSupportBundle(path, c.Name, log)
+	if err != nil {
+		log.Info("Failed to upload support bundle: %v", err)
+		return
+	}
+	if url != "" {
+		log.Info("Support bundle: %s", url)
+		return
 	}
+	log.Attach("Support bundle", path)
+}
+
+// waitForKOTSApplicationReady polls the app status until it meets the ready criteria or the ready timeout expires
+func waitForKOTSApplicationReady(ctx context.Context, clients *cluster.Clients, kotsAppSpec *types.KOTSApplicationSpec, appSlug string, pathToKOTSBinary string, opts waitOptions, log logger.Logger) error {
+	waitUntil := time.Now().Add(opts.readyTimeout)
+	pollInterval := opts.pollInterval
+
+	var lastError error
+	for {
+		appStatus, err := getKOTSApplicationStatus(ctx, clients, kotsAppSpec, appSlug, pathToKOTSBinary, opts.statusTimeout, log)
+		if err != nil {
+			lastError = err
+		} else {
+			statusString, _ := json.MarshalIndent(appStatus, "", "  ")
+			log.Debug("%s", statusString)
+			log.Info("%s", appStatus.AppStatus.Summary())
+			if opts.isReady(&appStatus.AppStatus) {
+				return nil
+			}
+			lastError = nil
+		}
 
-	<-finished
+		if time.Now().After(waitUntil) {
+			if lastError != nil {
+				return errors.Wrap(lastError, "timed out waiting for app ready status")
+			}
+			return errors.New("timed out waiting for app ready status")
+		}
 
-	return
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+		pollInterval = opts.nextPollInterval(pollInterval)
+	}
 }
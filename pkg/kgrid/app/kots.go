@@ -1,9 +1,8 @@
 package app
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,12 +10,12 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/gosimple/slug"
 	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/binaries"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
 	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
@@ -46,54 +45,104 @@ type ResourceState struct {
 	State     string `json:"state"`
 }
 
-func getAppSlug(c *types.ClusterConfig, kotsAppSpec *types.KOTSApplicationSpec) (string, error) {
-	// this is _really_ brittle
-	// the KOTS admin console doesn't give us a way to predict the app slug
-	// so we've copied the same logic that KOTS uses
-	// and it's sort of ok, but definitely is going to screw up
+// Summary is the app state and how many resources are ready, with a line for each one that isn't
+func (s AppStatus) Summary() string {
+	ready := 0
+	notReady := []string{}
+	for _, resourceState := range s.ResourceStates {
+		if resourceState.State == "ready" {
+			ready++
+			continue
+		}
+		notReady = append(notReady, fmt.Sprintf("• %s %s/%s: %s", resourceState.Kind, resourceState.Namespace, resourceState.Name, resourceState.State))
+	}
+
+	lines := []string{fmt.Sprintf("App state: %s, %d/%d resources ready", s.State, ready, len(s.ResourceStates))}
+	return strings.Join(append(lines, notReady...), "\n")
+}
 
-	// let's make kots return a list of apps?
-	licenseFilePath, err := downloadKOTSLicense(kotsAppSpec.Endpoint, kotsAppSpec.App, kotsAppSpec.LicenseID)
+type KOTSApp struct {
+	Slug  string `json:"slug"`
+	State string `json:"state,omitempty"`
+}
+
+// getKOTSAppSlug asks the admin console in the cluster which apps are installed.
+// This should be called once after the app is deployed, the slug doesn't change.
+func getKOTSAppSlug(ctx context.Context, clients *cluster.Clients, kotsAppSpec *types.KOTSApplicationSpec, pathToKOTSBinary string) (string, error) {
+	apps, err := listKOTSApps(ctx, clients, getKOTSNamespace(kotsAppSpec), pathToKOTSBinary)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to download license")
+		return "", errors.Wrap(err, "failed to list apps")
 	}
-	defer os.RemoveAll(licenseFilePath)
 
-	license, err := kotsutil.LoadLicenseFromPath(licenseFilePath)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to load license")
+	if len(apps) == 0 {
+		return "", errors.New("no apps installed")
 	}
 
-	desiredAppName := strings.Replace(license.Spec.AppSlug, "-", " ", 0)
-	titleForSlug := strings.Replace(desiredAppName, ".", "-", 0)
-	return slug.Make(titleForSlug), nil
-}
+	for _, app := range apps {
+		if app.Slug == kotsAppSpec.App {
+			return app.Slug, nil
+		}
+	}
 
-func getKOTSApplicationStatus(c *types.ClusterConfig, kotsAppSpec *types.KOTSApplicationSpec, pathToKOTSBinary string, log logger.Logger) (*AppStatusResponse, error) {
-	log.Info("Checking %s status", kotsAppSpec.App)
+	if len(apps) == 1 {
+		return apps[0].Slug, nil
+	}
 
-	kubeconfigFile, err := ioutil.TempFile("", "kots")
+	return "", errors.Errorf("unable to choose between %d installed apps", len(apps))
+}
+
+func listKOTSApps(ctx context.Context, clients *cluster.Clients, namespace string, pathToKOTSBinary string) ([]KOTSApp, error) {
+	kubeconfigFile, removeKubeconfig, err := clients.KubeconfigFile()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create temp file")
+		return nil, errors.Wrap(err, "failed to write kubeconfig")
+	}
+	defer removeKubeconfig()
+
+	allArgs := []string{
+		"get", "apps",
+		"-n", namespace,
+		"-o", "json",
+		"--kubeconfig", kubeconfigFile,
 	}
-	defer os.RemoveAll(kubeconfigFile.Name())
-	if err := ioutil.WriteFile(kubeconfigFile.Name(), []byte(c.Kubeconfig), 0644); err != nil {
-		return nil, errors.Wrap(err, "failed to create kubeconfig")
+	cmd := exec.CommandContext(ctx, pathToKOTSBinary, allArgs...)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to run kots get apps\nSTDOUT:%s\nSTDERR:%s", stdout.String(), stderr.String())
 	}
 
-	namespace := kotsAppSpec.Namespace
-	if namespace == "" {
-		namespace = kotsAppSpec.App
+	apps := []KOTSApp{}
+	if err := json.Unmarshal(stdout.Bytes(), &apps); err != nil {
+		return nil, errors.Wrap(err, "failed to parse apps")
 	}
 
-	appSlug, err := getAppSlug(c, kotsAppSpec)
+	return apps, nil
+}
+
+func getKOTSNamespace(kotsAppSpec *types.KOTSApplicationSpec) string {
+	if kotsAppSpec.Namespace != "" {
+		return kotsAppSpec.Namespace
+	}
+	return kotsAppSpec.App
+}
+
+func getKOTSApplicationStatus(ctx context.Context, clients *cluster.Clients, kotsAppSpec *types.KOTSApplicationSpec, appSlug string, pathToKOTSBinary string, statusTimeout time.Duration, log logger.Logger) (*AppStatusResponse, error) {
+	log.Info("Checking %s status", kotsAppSpec.App)
+
+	kubeconfigFile, removeKubeconfig, err := clients.KubeconfigFile()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get app slug")
+		return nil, errors.Wrap(err, "failed to write kubeconfig")
 	}
+	defer removeKubeconfig()
+
+	namespace := getKOTSNamespace(kotsAppSpec)
 
 	args := []string{
 		"--namespace", namespace,
-		"--kubeconfig", kubeconfigFile.Name(),
+		"--kubeconfig", kubeconfigFile,
 	}
 
 	allArgs := []string{
@@ -102,23 +151,29 @@ func getKOTSApplicationStatus(c *types.ClusterConfig, kotsAppSpec *types.KOTSApp
 		appSlug,
 	}
 	allArgs = append(allArgs, args...)
-	cmd := exec.Command(pathToKOTSBinary, allArgs...)
+	cmd := exec.CommandContext(ctx, pathToKOTSBinary, allArgs...)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	cmd.Start()
-	done := make(chan error)
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "failed to start kots for status check")
+	}
+	done := make(chan error, 1)
 	go func() {
 		done <- cmd.Wait()
 	}()
-	timeout := time.After(time.Second * 20)
+	timeout := time.After(statusTimeout)
 
 	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	case <-timeout:
+		// wait for exec to finish copying output before reading the buffers
 		cmd.Process.Kill()
-		return nil, errors.Errorf("timedout waiting for app ready.\nSTDOUT:%s\nSTDERR:%s", stdout.String(), stderr.String())
+		<-done
+		return nil, errors.Errorf("timed out waiting for app ready.\nSTDOUT:%s\nSTDERR:%s", stdout.String(), stderr.String())
 	case err := <-done:
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to run kots for status check\nSTDOUT:%s\nSTDERR:%s", stdout.String(), stderr.String())
@@ -133,37 +188,30 @@ func getKOTSApplicationStatus(c *types.ClusterConfig, kotsAppSpec *types.KOTSApp
 	}
 }
 
-func deployKOTSApplication(c *types.ClusterConfig, kotsAppSpec *types.KOTSApplicationSpec, pathToKOTSBinary string, log logger.Logger) error {
+func deployKOTSApplication(ctx context.Context, clients *cluster.Clients, kotsAppSpec *types.KOTSApplicationSpec, pathToKOTSBinary string, installTimeout time.Duration, log logger.Logger) error {
 	log.Info("Deploying app %s", kotsAppSpec.App)
 
-	pathToLicense, err := downloadKOTSLicense(kotsAppSpec.Endpoint, kotsAppSpec.App, kotsAppSpec.LicenseID)
+	pathToLicense, err := downloadKOTSLicense(ctx, kotsAppSpec.Endpoint, kotsAppSpec.App, kotsAppSpec.LicenseID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get license")
 	}
 	defer os.RemoveAll(pathToLicense)
 
-	kubeconfigFile, err := ioutil.TempFile("", "kots")
+	kubeconfigFile, removeKubeconfig, err := clients.KubeconfigFile()
 	if err != nil {
-		return errors.Wrap(err, "failed to create temp file")
-	}
-	defer os.RemoveAll(kubeconfigFile.Name())
-
-	if err := ioutil.WriteFile(kubeconfigFile.Name(), []byte(c.Kubeconfig), 0644); err != nil {
-		return errors.Wrap(err, "failed to create kubeconfig")
+		return errors.Wrap(err, "failed to write kubeconfig")
 	}
+	defer removeKubeconfig()
 
-	namespace := kotsAppSpec.Namespace
-	if namespace == "" {
-		namespace = kotsAppSpec.App
-	}
+	namespace := getKOTSNamespace(kotsAppSpec)
 
 	args := []string{
 		"--namespace", namespace,
 		"--license-file", pathToLicense,
 		"--shared-password", "password",
-		"--wait-duration", "10m",
+		"--wait-duration", installTimeout.String(),
 		"--port-forward=false",
-		"--kubeconfig", kubeconfigFile.Name(),
+		"--kubeconfig", kubeconfigFile,
 	}
 
 	if kotsAppSpec.ConfigValues != nil {
@@ -189,7 +237,7 @@ func deployKOTSApplication(c *types.ClusterConfig, kotsAppSpec *types.KOTSApplic
 			return errors.Wrap(err, "failed to create temp file")
 		}
 		defer os.RemoveAll(configValuesFile.Name())
-		if err := ioutil.WriteFile(configValuesFile.Name(), []byte(b), 0644); err != nil {
+		if err := ioutil.WriteFile(configValuesFile.Name(), []byte(b), 0600); err != nil {
 			return errors.Wrap(err, "failed to write config values to file")
 		}
 		args = append(args, "--config-values")
@@ -206,23 +254,29 @@ func deployKOTSApplication(c *types.ClusterConfig, kotsAppSpec *types.KOTSApplic
 	}
 	allArgs = append(allArgs, args...)
 
-	cmd := exec.Command(pathToKOTSBinary, allArgs...)
+	cmd := exec.CommandContext(ctx, pathToKOTSBinary, allArgs...)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	cmd.Start()
-	done := make(chan error)
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "failed to start kots for deploy")
+	}
+	done := make(chan error, 1)
 	go func() {
 		done <- cmd.Wait()
 	}()
-	timeout := time.After(10 * time.Minute) // TODO: app deploy already has a timeout
+	timeout := time.After(installTimeout)
 
 	select {
+	case <-ctx.Done():
+		return ctx.Err()
 	case <-timeout:
+		// wait for exec to finish copying output before reading the buffers
 		cmd.Process.Kill()
-		log.Info("timeoud out deploying app.  received std out: %s", stdout.String())
+		<-done
+		return errors.Errorf("timed out deploying app after %s\nSTDOUT:%s\nSTDERR:%s", installTimeout, stdout.String(), stderr.String())
 	case err := <-done:
 		if err != nil {
 			return errors.Wrapf(err, "failed to run kots for deploy\nSTDOUT:%s\nSTDERR:%s", stdout.String(), stderr.String())
@@ -235,13 +289,13 @@ func deployKOTSApplication(c *types.ClusterConfig, kotsAppSpec *types.KOTSApplic
 }
 
 // the caller is responsible for deleting the file
-func downloadKOTSLicense(endpoint string, appSlug string, licenseID string) (string, error) {
+func downloadKOTSLicense(ctx context.Context, endpoint string, appSlug string, licenseID string) (string, error) {
 	if endpoint == "" {
 		endpoint = "https://replicated.app"
 	}
 	url := fmt.Sprintf("%s/license/%s", endpoint, appSlug)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create new request")
 	}
@@ -269,75 +323,15 @@ func downloadKOTSLicense(endpoint string, appSlug string, licenseID string) (str
 	return archiveFile.Name(), nil
 }
 
-func downloadKOTSBinary(version string) (string, error) {
+func downloadKOTSBinary(ctx context.Context, version string) (string, error) {
 	if version == "" {
 		version = DefaultKOTSVersion
 	}
 
-	url := fmt.Sprintf("https://github.com/replicatedhq/kots/releases/download/%s/kots_linux_amd64.tar.gz", version)
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", errors.Wrapf(err, "failed to http get kots from %s", url)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", errors.Errorf("failed to download from %s, unexpected status code %d", url, resp.StatusCode)
-	}
-
-	archiveFile, err := ioutil.TempFile("", "kots")
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create temp archive file")
-	}
-	defer os.RemoveAll(archiveFile.Name())
-
-	_, err = io.Copy(archiveFile, resp.Body)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to save archive file")
-	}
-
-	if _, err := archiveFile.Seek(0, 0); err != nil {
-		return "", errors.Wrap(err, "failed to seek")
-	}
-
-	gzf, err := gzip.NewReader(archiveFile)
+	cache, err := binaries.NewCache()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create gzip reader")
-	}
-
-	tarReader := tar.NewReader(gzf)
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-
-		if err != nil {
-			return "", errors.Wrap(err, "failed to read next file")
-		}
-
-		name := header.Name
-		if name == "kots" {
-			tmpDir, err := ioutil.TempDir("", "kots")
-			if err != nil {
-				return "", errors.Wrap(err, "failed to create tmp dir")
-			}
-
-			binaryPath := filepath.Join(tmpDir, "kots")
-			f, err := os.Create(binaryPath)
-			if err != nil {
-				return "", errors.Wrap(err, "failed to os create file")
-			}
-			defer f.Close()
-
-			if _, err := io.Copy(f, tarReader); err != nil {
-				return "", errors.Wrap(err, "failed to copy kots binary")
-			}
-			if err := os.Chmod(binaryPath, 0777); err != nil {
-				return "", errors.Wrap(err, "faild to chmod")
-			}
-
-			return binaryPath, nil
-		}
+		return "", errors.Wrap(err, "failed to create binary cache")
 	}
 
-	return "", errors.New("kots binary not found in release")
+	return cache.Get(ctx, binaries.KOTS, version)
 }
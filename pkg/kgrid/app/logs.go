@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// appSlugLabelKey is added by kots to every resource it deploys for an app
+const appSlugLabelKey = "kots.io/app-slug"
+
+// FindAppPods returns the pods kots deployed for the app, and the admin console pods in the same namespaces.
+// If the app has no pods yet, the admin console pods in every namespace are returned.
+func FindAppPods(ctx context.Context, clients *cluster.Clients, appSlug string) ([]corev1.Pod, error) {
+	appPods, err := clients.Kubernetes.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", appSlugLabelKey, appSlug),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list app pods")
+	}
+
+	kotsadmPods, err := clients.Kubernetes.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		LabelSelector: kotsadmSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list kotsadm pods")
+	}
+
+	appNamespaces := map[string]bool{}
+	for _, pod := range appPods.Items {
+		appNamespaces[pod.Namespace] = true
+	}
+
+	pods := []corev1.Pod{}
+	for _, pod := range kotsadmPods.Items {
+		if len(appNamespaces) == 0 || appNamespaces[pod.Namespace] {
+			pods = append(pods, pod)
+		}
+	}
+	pods = append(pods, appPods.Items...)
+
+	return pods, nil
+}
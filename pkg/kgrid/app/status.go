@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kotsadmSelector matches the admin console pods that kots installs alongside each app
+const kotsadmSelector = "app=kotsadm"
+
+// DeployedApp is a KOTS app found running in a cluster
+type DeployedApp struct {
+	Namespace string `json:"namespace"`
+	Slug      string `json:"slug"`
+	State     string `json:"state,omitempty"`
+}
+
+// FindKOTSNamespaces returns the namespaces in the cluster that have a KOTS admin console
+func FindKOTSNamespaces(ctx context.Context, clients *cluster.Clients) ([]string, error) {
+	pods, err := clients.Kubernetes.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		LabelSelector: kotsadmSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list kotsadm pods")
+	}
+
+	seen := map[string]bool{}
+	namespaces := []string{}
+	for _, pod := range pods.Items {
+		if seen[pod.Namespace] {
+			continue
+		}
+		seen[pod.Namespace] = true
+		namespaces = append(namespaces, pod.Namespace)
+	}
+
+	return namespaces, nil
+}
+
+// ListDeployedApps asks the admin console in each namespace which apps it has installed, and their state
+func ListDeployedApps(ctx context.Context, clients *cluster.Clients, namespaces []string, pathToKOTSBinary string) ([]DeployedApp, error) {
+	deployedApps := []DeployedApp{}
+	for _, namespace := range namespaces {
+		apps, err := listKOTSApps(ctx, clients, namespace, pathToKOTSBinary)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list apps in namespace %s", namespace)
+		}
+
+		for _, a := range apps {
+			deployedApps = append(deployedApps, DeployedApp{
+				Namespace: namespace,
+				Slug:      a.Slug,
+				State:     a.State,
+			})
+		}
+	}
+
+	return deployedApps, nil
+}
+
+// GetKOTSBinary returns the path to the default version of the kots binary, downloading it if needed
+func GetKOTSBinary(ctx context.Context) (string, error) {
+	return downloadKOTSBinary(ctx, "")
+}
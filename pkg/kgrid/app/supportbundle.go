@@ -1,14 +1,10 @@
 package app
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -19,49 +15,178 @@ import (
 	awssession "github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/binaries"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
 )
 
-func generateSupportBundle(c *types.ClusterConfig, log logger.Logger) (string, error) {
-	pathToSupportBundleBinary, err := downloadSupportBundleBinary()
+// supportBundleURLExpiry is how long a support bundle link works, the most s3 allows
+const supportBundleURLExpiry = 7 * 24 * time.Hour
+
+// defaultSupportBundleSpec is collected when the app spec doesn't list any
+const defaultSupportBundleSpec = "https://kots.io"
+
+type supportBundleOptions struct {
+	collect   string
+	specs     []types.SupportBundleSource
+	redactors []types.SupportBundleSource
+}
+
+func getSupportBundleOptions(spec types.ApplicationSpec) (supportBundleOptions, error) {
+	opts := supportBundleOptions{
+		collect: types.SupportBundleCollectOnFailure,
+		specs:   []types.SupportBundleSource{{URL: defaultSupportBundleSpec}},
+	}
+	if spec.SupportBundle == nil {
+		return opts, nil
+	}
+
+	switch spec.SupportBundle.Collect {
+	case "":
+	case types.SupportBundleCollectAlways, types.SupportBundleCollectOnFailure, types.SupportBundleCollectNever:
+		opts.collect = spec.SupportBundle.Collect
+	default:
+		return opts, errors.Errorf("unknown collect policy %q, must be one of always, onFailure, or never", spec.SupportBundle.Collect)
+	}
+
+	for i, source := range spec.SupportBundle.Specs {
+		if err := validateSupportBundleSource(source); err != nil {
+			return opts, errors.Wrapf(err, "spec %d", i)
+		}
+		if source.App && spec.KOTSApplicationSpec == nil {
+			return opts, errors.Errorf("spec %d: app is only supported for kots applications", i)
+		}
+	}
+	if len(spec.SupportBundle.Specs) > 0 {
+		opts.specs = spec.SupportBundle.Specs
+	}
+
+	for i, source := range spec.SupportBundle.Redactors {
+		if err := validateSupportBundleSource(source); err != nil {
+			return opts, errors.Wrapf(err, "redactor %d", i)
+		}
+		if source.App {
+			return opts, errors.Errorf("redactor %d: app is only supported for specs", i)
+		}
+	}
+	opts.redactors = spec.SupportBundle.Redactors
+
+	return opts, nil
+}
+
+func validateSupportBundleSource(source types.SupportBundleSource) error {
+	set := 0
+	for _, isSet := range []bool{source.Inline != "", source.URL != "", source.App} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of inline, url, or app must be set")
+	}
+	return nil
+}
+
+// shouldCollect is true if a bundle should be collected after a deploy that returned deployErr
+func (o supportBundleOptions) shouldCollect(deployErr error) bool {
+	switch o.collect {
+	case types.SupportBundleCollectAlways:
+		return true
+	case types.SupportBundleCollectOnFailure:
+		return deployErr != nil
+	}
+	return false
+}
+
+// supportBundleArgs turns each source into an argument for the support-bundle cli.
+// Inline specs are written to dir, named after prefix. appSpec is only called if a source needs it.
+func supportBundleArgs(sources []types.SupportBundleSource, dir string, prefix string, appSpec func() (string, error)) ([]string, error) {
+	args := []string{}
+	for i, source := range sources {
+		switch {
+		case source.Inline != "":
+			path := filepath.Join(dir, fmt.Sprintf("%s-%d.yaml", prefix, i))
+			if err := os.WriteFile(path, []byte(source.Inline), 0600); err != nil {
+				return nil, errors.Wrapf(err, "failed to write %s %d", prefix, i)
+			}
+			args = append(args, path)
+		case source.URL != "":
+			args = append(args, source.URL)
+		case source.App:
+			ref, err := appSpec()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to find app support bundle spec")
+			}
+			args = append(args, ref)
+		}
+	}
+	return args, nil
+}
+
+// kotsAppSupportBundleSpec is the secret that KOTS keeps the app's support bundle spec in, in the form support-bundle reads
+func kotsAppSupportBundleSpec(namespace string, appSlug string) string {
+	return fmt.Sprintf("secret/%s/kotsadm-%s-supportbundle", namespace, appSlug)
+}
+
+func generateSupportBundle(ctx context.Context, clients *cluster.Clients, opts supportBundleOptions, appSpec func() (string, error), log logger.Logger) (string, error) {
+	pathToSupportBundleBinary, err := downloadSupportBundleBinary(ctx)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get support-bundle binary")
 	}
 
-	kubeconfigFile, err := ioutil.TempFile("", "supportbundle")
+	kubeconfigFile, removeKubeconfig, err := clients.KubeconfigFile()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create temp file")
+		return "", errors.Wrap(err, "failed to write kubeconfig")
 	}
-	defer os.RemoveAll(kubeconfigFile.Name())
+	defer removeKubeconfig()
 
-	if err := ioutil.WriteFile(kubeconfigFile.Name(), []byte(c.Kubeconfig), 0644); err != nil {
-		return "", errors.Wrap(err, "failed to create kubeconfig")
+	specsDir, err := os.MkdirTemp("", "kgrid-support-bundle-specs")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create specs dir")
 	}
+	defer os.RemoveAll(specsDir)
 
-	args := []string{
-		"https://kots.io",
-		"--kubeconfig", kubeconfigFile.Name(),
+	args, err := supportBundleArgs(opts.specs, specsDir, "spec", appSpec)
+	if err != nil {
+		return "", err
+	}
+	redactorArgs, err := supportBundleArgs(opts.redactors, specsDir, "redactor", appSpec)
+	if err != nil {
+		return "", err
+	}
+
+	args = append(args,
+		"--kubeconfig", kubeconfigFile,
 		"--interactive=false",
+	)
+	for _, redactor := range redactorArgs {
+		args = append(args, "--redactors", redactor)
 	}
 
-	cmd := exec.Command(pathToSupportBundleBinary, args...)
+	cmd := exec.CommandContext(ctx, pathToSupportBundleBinary, args...)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	cmd.Start()
-	done := make(chan error)
+	if err := cmd.Start(); err != nil {
+		return "", errors.Wrap(err, "failed to start support-bundle")
+	}
+	done := make(chan error, 1)
 	go func() {
 		done <- cmd.Wait()
 	}()
 	timeout := time.After(5 * time.Minute)
 
 	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
 	case <-timeout:
+		// wait for exec to finish copying output before reading the buffers
 		cmd.Process.Kill()
-		log.Info("timed out generating a support bundle. received std out: %s", stdout.String())
+		<-done
+		return "", errors.Errorf("timed out generating a support bundle\nSTDOUT:%s\nSTDERR:%s", stdout.String(), stderr.String())
 	case err := <-done:
 		if err != nil {
 			return "", errors.Wrapf(err, "failed to generate a support bundle\nSTDOUT:%s\nSTDERR:%s", stdout.String(), stderr.String())
@@ -81,26 +206,29 @@ func generateSupportBundle(c *types.ClusterConfig, log logger.Logger) (string, e
 	return output.ArchivePath, nil
 }
 
-func uploadSupportBundle(path string, log logger.Logger) error {
+// uploadSupportBundle puts the bundle in AWS_S3_BUCKET and returns a presigned url to download it.
+// The url is empty if there's no bucket to upload to.
+func uploadSupportBundle(path string, clusterName string, log logger.Logger) (string, error) {
 	if os.Getenv("AWS_S3_BUCKET") == "" {
 		log.Info("bucket not specified, not going to upload the support bundle.")
-		return nil
+		return "", nil
 	}
 	if os.Getenv("AWS_S3_ACCESS_KEY_ID") == "" || os.Getenv("AWS_S3_SECRET_ACCESS_KEY") == "" {
 		log.Info("missing aws credentials, not going to upload the support bundle.")
-		return nil
+		return "", nil
 	}
 
 	f, err := os.Open(path)
 	if err != nil {
-		return errors.Wrap(err, "failed to open archive file")
+		return "", errors.Wrap(err, "failed to open archive file")
 	}
 	defer f.Close()
 
 	newSession := awssession.New(getS3Config())
 	s3Client := s3.New(newSession)
 
-	key := fmt.Sprintf("%s.tar.gz", os.Getenv("TEST_ID"))
+	// bundles can be collected from every cluster in a test, so each gets its own key
+	key := fmt.Sprintf("%s-%s.tar.gz", os.Getenv("TEST_ID"), clusterName)
 	if prefix := os.Getenv("RUN_ID"); prefix != "" {
 		key = fmt.Sprintf("%s/%s", prefix, key)
 	}
@@ -111,79 +239,28 @@ func uploadSupportBundle(path string, log logger.Logger) error {
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return errors.Wrap(err, "failed to upload to s3")
+		return "", errors.Wrap(err, "failed to upload to s3")
 	}
 
-	return nil
-}
-
-func downloadSupportBundleBinary() (string, error) {
-	url := fmt.Sprintf("https://github.com/replicatedhq/troubleshoot/releases/latest/download/support-bundle_linux_amd64.tar.gz")
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", errors.Wrapf(err, "failed to http get support-bundle binary from %s", url)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", errors.Errorf("failed to download from %s, unexpected status code %d", url, resp.StatusCode)
-	}
-
-	archiveFile, err := ioutil.TempFile("", "supportbundle")
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create temp archive file")
-	}
-	defer os.RemoveAll(archiveFile.Name())
-
-	_, err = io.Copy(archiveFile, resp.Body)
+	req, _ := s3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(os.Getenv("AWS_S3_BUCKET")),
+		Key:    aws.String(key),
+	})
+	url, err := req.Presign(supportBundleURLExpiry)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to save archive file")
+		return "", errors.Wrap(err, "failed to presign support bundle url")
 	}
 
-	if _, err := archiveFile.Seek(0, 0); err != nil {
-		return "", errors.Wrap(err, "failed to seek")
-	}
+	return url, nil
+}
 
-	gzf, err := gzip.NewReader(archiveFile)
+func downloadSupportBundleBinary(ctx context.Context) (string, error) {
+	cache, err := binaries.NewCache()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create gzip reader")
-	}
-
-	tarReader := tar.NewReader(gzf)
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-
-		if err != nil {
-			return "", errors.Wrap(err, "failed to read next file")
-		}
-
-		name := header.Name
-		if name == "support-bundle" {
-			tmpDir, err := ioutil.TempDir("", "supportbundle")
-			if err != nil {
-				return "", errors.Wrap(err, "failed to create tmp dir")
-			}
-
-			binaryPath := filepath.Join(tmpDir, "support-bundle")
-			f, err := os.Create(binaryPath)
-			if err != nil {
-				return "", errors.Wrap(err, "failed to os create file")
-			}
-			defer f.Close()
-
-			if _, err := io.Copy(f, tarReader); err != nil {
-				return "", errors.Wrap(err, "failed to copy support-bundle binary")
-			}
-			if err := os.Chmod(binaryPath, 0777); err != nil {
-				return "", errors.Wrap(err, "faild to chmod")
-			}
-
-			return binaryPath, nil
-		}
+		return "", errors.Wrap(err, "failed to create binary cache")
 	}
 
-	return "", errors.New("support-bundle binary not found in release")
+	return cache.Get(ctx, binaries.SupportBundle, binaries.LatestVersion)
 }
 
 func getS3Config() *aws.Config {
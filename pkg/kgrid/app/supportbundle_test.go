@@ -0,0 +1,133 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getSupportBundleOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    types.ApplicationSpec
+		want    supportBundleOptions
+		wantErr string
+	}{
+		{
+			name: "default",
+			want: supportBundleOptions{
+				collect: types.SupportBundleCollectOnFailure,
+				specs:   []types.SupportBundleSource{{URL: defaultSupportBundleSpec}},
+			},
+		},
+		{
+			name: "custom",
+			spec: types.ApplicationSpec{
+				KOTSApplicationSpec: &types.KOTSApplicationSpec{App: "app"},
+				SupportBundle: &types.SupportBundleSpec{
+					Collect:   types.SupportBundleCollectAlways,
+					Specs:     []types.SupportBundleSource{{App: true}, {Inline: "kind: SupportBundle"}},
+					Redactors: []types.SupportBundleSource{{URL: "https://example.com/redact.yaml"}},
+				},
+			},
+			want: supportBundleOptions{
+				collect:   types.SupportBundleCollectAlways,
+				specs:     []types.SupportBundleSource{{App: true}, {Inline: "kind: SupportBundle"}},
+				redactors: []types.SupportBundleSource{{URL: "https://example.com/redact.yaml"}},
+			},
+		},
+		{
+			name: "unknown policy",
+			spec: types.ApplicationSpec{
+				SupportBundle: &types.SupportBundleSpec{Collect: "sometimes"},
+			},
+			wantErr: `unknown collect policy "sometimes", must be one of always, onFailure, or never`,
+		},
+		{
+			name: "two sources",
+			spec: types.ApplicationSpec{
+				SupportBundle: &types.SupportBundleSpec{
+					Specs: []types.SupportBundleSource{{URL: "https://kots.io", Inline: "kind: SupportBundle"}},
+				},
+			},
+			wantErr: "spec 0: exactly one of inline, url, or app must be set",
+		},
+		{
+			name: "app without kots",
+			spec: types.ApplicationSpec{
+				SupportBundle: &types.SupportBundleSpec{
+					Specs: []types.SupportBundleSource{{App: true}},
+				},
+			},
+			wantErr: "spec 0: app is only supported for kots applications",
+		},
+		{
+			name: "app redactor",
+			spec: types.ApplicationSpec{
+				KOTSApplicationSpec: &types.KOTSApplicationSpec{App: "app"},
+				SupportBundle: &types.SupportBundleSpec{
+					Redactors: []types.SupportBundleSource{{App: true}},
+				},
+			},
+			wantErr: "redactor 0: app is only supported for specs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			got, err := getSupportBundleOptions(tt.spec)
+			if tt.wantErr != "" {
+				req.EqualError(err, tt.wantErr)
+				return
+			}
+			req.NoError(err)
+			req.Equal(tt.want, got)
+		})
+	}
+}
+
+func Test_shouldCollect(t *testing.T) {
+	req := require.New(t)
+
+	failed := errors.New("deploy failed")
+	for collect, want := range map[string][2]bool{
+		types.SupportBundleCollectAlways:    {true, true},
+		types.SupportBundleCollectOnFailure: {false, true},
+		types.SupportBundleCollectNever:     {false, false},
+	} {
+		opts := supportBundleOptions{collect: collect}
+		req.Equal(want[0], opts.shouldCollect(nil), collect)
+		req.Equal(want[1], opts.shouldCollect(failed), collect)
+	}
+}
+
+func Test_supportBundleArgs(t *testing.T) {
+	req := require.New(t)
+
+	dir := t.TempDir()
+	appSpecCalls := 0
+	appSpec := func() (string, error) {
+		appSpecCalls++
+		return kotsAppSupportBundleSpec("default", "my-app"), nil
+	}
+
+	args, err := supportBundleArgs([]types.SupportBundleSource{
+		{URL: "https://kots.io"},
+		{Inline: "kind: SupportBundle"},
+		{App: true},
+	}, dir, "spec", appSpec)
+	req.NoError(err)
+
+	inlinePath := filepath.Join(dir, "spec-1.yaml")
+	req.Equal([]string{"https://kots.io", inlinePath, "secret/default/kotsadm-my-app-supportbundle"}, args)
+	req.Equal(1, appSpecCalls)
+
+	data, err := os.ReadFile(inlinePath)
+	req.NoError(err)
+	req.Equal("kind: SupportBundle", string(data))
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"time"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+)
+
+const (
+	DefaultInstallTimeout = 10 * time.Minute
+	DefaultReadyTimeout   = 5 * time.Minute
+	DefaultStatusTimeout  = 20 * time.Second
+	DefaultPollInterval   = 10 * time.Second
+)
+
+type waitOptions struct {
+	installTimeout  time.Duration
+	readyTimeout    time.Duration
+	statusTimeout   time.Duration
+	pollInterval    time.Duration
+	backoffFactor   float64
+	maxPollInterval time.Duration
+	readyStates     []string
+	tolerate        []types.ResourceSelector
+}
+
+func getWaitOptions(spec *types.WaitSpec) waitOptions {
+	opts := waitOptions{
+		installTimeout: DefaultInstallTimeout,
+		readyTimeout:   DefaultReadyTimeout,
+		statusTimeout:  DefaultStatusTimeout,
+		pollInterval:   DefaultPollInterval,
+		backoffFactor:  1,
+		readyStates:    []string{"ready"},
+	}
+
+	if spec == nil {
+		return opts
+	}
+
+	if spec.InstallTimeout != nil && spec.InstallTimeout.Duration > 0 {
+		opts.installTimeout = spec.InstallTimeout.Duration
+	}
+	if spec.ReadyTimeout != nil && spec.ReadyTimeout.Duration > 0 {
+		opts.readyTimeout = spec.ReadyTimeout.Duration
+	}
+	if spec.StatusTimeout != nil && spec.StatusTimeout.Duration > 0 {
+		opts.statusTimeout = spec.StatusTimeout.Duration
+	}
+	if spec.PollInterval != nil && spec.PollInterval.Duration > 0 {
+		opts.pollInterval = spec.PollInterval.Duration
+	}
+	if spec.Backoff != nil {
+		if spec.Backoff.Factor > 1 {
+			opts.backoffFactor = spec.Backoff.Factor
+		}
+		if spec.Backoff.MaxInterval != nil {
+			opts.maxPollInterval = spec.Backoff.MaxInterval.Duration
+		}
+	}
+	if spec.Ready != nil {
+		if len(spec.Ready.States) > 0 {
+			opts.readyStates = spec.Ready.States
+		}
+		opts.tolerate = spec.Ready.TolerateDegraded
+	}
+
+	return opts
+}
+
+// nextPollInterval applies the backoff policy to the current interval
+func (o waitOptions) nextPollInterval(current time.Duration) time.Duration {
+	next := time.Duration(float64(current) * o.backoffFactor)
+	if o.maxPollInterval > 0 && next > o.maxPollInterval {
+		return o.maxPollInterval
+	}
+	return next
+}
+
+// isReady returns true if the app status meets the ready criteria.
+// An app that isn't in a ready state is still ready if every resource that is not ready
+// is degraded and matches one of the tolerated resources.
+func (o waitOptions) isReady(appStatus *AppStatus) bool {
+	for _, state := range o.readyStates {
+		if appStatus.State == state {
+			return true
+		}
+	}
+
+	if len(o.tolerate) == 0 || len(appStatus.ResourceStates) == 0 {
+		return false
+	}
+
+	for _, resourceState := range appStatus.ResourceStates {
+		if resourceState.State == "ready" {
+			continue
+		}
+		if resourceState.State == "degraded" && o.isTolerated(resourceState) {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
+
+func (o waitOptions) isTolerated(resourceState ResourceState) bool {
+	for _, selector := range o.tolerate {
+		if selector.Name != resourceState.Name {
+			continue
+		}
+		if selector.Kind != "" && selector.Kind != resourceState.Kind {
+			continue
+		}
+		if selector.Namespace != "" && selector.Namespace != resourceState.Namespace {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_isReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      *types.WaitSpec
+		appStatus AppStatus
+		expected  bool
+	}{
+		{
+			name:      "default ready",
+			appStatus: AppStatus{State: "ready"},
+			expected:  true,
+		},
+		{
+			name:      "default degraded",
+			appStatus: AppStatus{State: "degraded"},
+			expected:  false,
+		},
+		{
+			name: "custom ready states",
+			spec: &types.WaitSpec{
+				Ready: &types.ReadyCriteria{States: []string{"ready", "degraded"}},
+			},
+			appStatus: AppStatus{State: "degraded"},
+			expected:  true,
+		},
+		{
+			name: "tolerated degraded resource",
+			spec: &types.WaitSpec{
+				Ready: &types.ReadyCriteria{
+					TolerateDegraded: []types.ResourceSelector{{Kind: "statefulset", Name: "db"}},
+				},
+			},
+			appStatus: AppStatus{
+				State: "degraded",
+				ResourceStates: []ResourceState{
+					{Kind: "deployment", Name: "web", Namespace: "app", State: "ready"},
+					{Kind: "statefulset", Name: "db", Namespace: "app", State: "degraded"},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "untolerated degraded resource",
+			spec: &types.WaitSpec{
+				Ready: &types.ReadyCriteria{
+					TolerateDegraded: []types.ResourceSelector{{Kind: "statefulset", Name: "db"}},
+				},
+			},
+			appStatus: AppStatus{
+				State: "degraded",
+				ResourceStates: []ResourceState{
+					{Kind: "deployment", Name: "web", Namespace: "app", State: "degraded"},
+					{Kind: "statefulset", Name: "db", Namespace: "app", State: "degraded"},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "tolerated resource that is unavailable",
+			spec: &types.WaitSpec{
+				Ready: &types.ReadyCriteria{
+					TolerateDegraded: []types.ResourceSelector{{Name: "db"}},
+				},
+			},
+			appStatus: AppStatus{
+				State: "unavailable",
+				ResourceStates: []ResourceState{
+					{Kind: "statefulset", Name: "db", Namespace: "app", State: "unavailable"},
+				},
+			},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := getWaitOptions(test.spec)
+			assert.Equal(t, test.expected, opts.isReady(&test.appStatus))
+		})
+	}
+}
+
+func Test_nextPollInterval(t *testing.T) {
+	opts := getWaitOptions(&types.WaitSpec{
+		PollInterval: &metav1.Duration{Duration: 5 * time.Second},
+		Backoff: &types.BackoffSpec{
+			Factor:      2,
+			MaxInterval: &metav1.Duration{Duration: 15 * time.Second},
+		},
+	})
+
+	interval := opts.pollInterval
+	assert.Equal(t, 5*time.Second, interval)
+	interval = opts.nextPollInterval(interval)
+	assert.Equal(t, 10*time.Second, interval)
+	interval = opts.nextPollInterval(interval)
+	assert.Equal(t, 15*time.Second, interval)
+
+	assert.Equal(t, DefaultPollInterval, getWaitOptions(nil).nextPollInterval(DefaultPollInterval))
+}
@@ -0,0 +1,447 @@
+package binaries
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultBaseURL = "https://github.com"
+
+	// LatestVersion is resolved to a concrete release tag before the cache is consulted
+	LatestVersion = "latest"
+
+	// DefaultLatestTTL is how long a resolved latest version is used before checking for a newer release
+	DefaultLatestTTL = 24 * time.Hour
+)
+
+// Tool describes a binary that is published as a tar.gz asset on a github release
+type Tool struct {
+	Name   string
+	Repo   string
+	Asset  string
+	Binary string
+
+	// ChecksumsAsset returns the name of the release asset containing sha256 sums for the version
+	ChecksumsAsset func(version string) string
+}
+
+var (
+	KOTS = Tool{
+		Name:   "kots",
+		Repo:   "replicatedhq/kots",
+		Asset:  "kots_linux_amd64.tar.gz",
+		Binary: "kots",
+		ChecksumsAsset: func(version string) string {
+			return fmt.Sprintf("kots_%s_checksums.txt", strings.TrimPrefix(version, "v"))
+		},
+	}
+
+	SupportBundle = Tool{
+		Name:   "support-bundle",
+		Repo:   "replicatedhq/troubleshoot",
+		Asset:  "support-bundle_linux_amd64.tar.gz",
+		Binary: "support-bundle",
+		ChecksumsAsset: func(version string) string {
+			return fmt.Sprintf("troubleshoot_%s_checksums.txt", strings.TrimPrefix(version, "v"))
+		},
+	}
+)
+
+// Cache stores verified binaries on disk. Extracted binaries are stored by the sha256 of the release
+// archive they came from, and an index file per tool and version points at the archive digest.
+type Cache struct {
+	Dir     string
+	BaseURL string
+	Client  *http.Client
+
+	// LatestTTL is how long a resolved latest version is reused. Zero resolves it on every Get.
+	LatestTTL time.Duration
+
+	now func() time.Time
+}
+
+type indexEntry struct {
+	ArchiveSHA256 string `json:"archiveSha256"`
+	BinarySHA256  string `json:"binarySha256"`
+}
+
+type latestEntry struct {
+	Version    string    `json:"version"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+// NewCache returns a cache in the user cache dir. KGRID_CACHE_DIR and KGRID_BINARY_MIRROR
+// override the location of the cache and the base url binaries are downloaded from.
+func NewCache() (*Cache, error) {
+	dir := os.Getenv("KGRID_CACHE_DIR")
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get user cache dir")
+		}
+		dir = filepath.Join(userCacheDir, "kgrid")
+	}
+
+	baseURL := os.Getenv("KGRID_BINARY_MIRROR")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Cache{
+		Dir:       filepath.Join(dir, "bin"),
+		BaseURL:   baseURL,
+		Client:    http.DefaultClient,
+		LatestTTL: DefaultLatestTTL,
+	}, nil
+}
+
+// Get returns the path to the tool binary for version, downloading and verifying it if it's not in the cache.
+// The returned path is owned by the cache and must not be deleted by the caller.
+func (c *Cache) Get(ctx context.Context, tool Tool, version string) (string, error) {
+	if version == LatestVersion {
+		resolved, err := c.latestVersion(ctx, tool)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to resolve latest %s version", tool.Name)
+		}
+		version = resolved
+	}
+
+	if binaryPath, ok := c.lookup(tool, version); ok {
+		return binaryPath, nil
+	}
+
+	checksums, err := c.fetchChecksums(ctx, tool, version)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get %s %s checksums", tool.Name, version)
+	}
+	expectedSHA256, ok := checksums[tool.Asset]
+	if !ok {
+		return "", errors.Errorf("no checksum for %s in %s %s release", tool.Asset, tool.Name, version)
+	}
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create cache dir")
+	}
+
+	archiveFile, err := ioutil.TempFile(c.Dir, "archive")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temp archive file")
+	}
+	defer os.RemoveAll(archiveFile.Name())
+	defer archiveFile.Close()
+
+	url := c.assetURL(tool, version, tool.Asset)
+	archiveSHA256, err := c.download(ctx, url, archiveFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to download %s", url)
+	}
+	if archiveSHA256 != expectedSHA256 {
+		return "", errors.Errorf("checksum mismatch for %s: expected %s, got %s", url, expectedSHA256, archiveSHA256)
+	}
+
+	if _, err := archiveFile.Seek(0, 0); err != nil {
+		return "", errors.Wrap(err, "failed to seek")
+	}
+
+	binaryPath := c.blobPath(tool, archiveSHA256)
+	binarySHA256, err := extractBinary(archiveFile, tool.Binary, binaryPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to extract %s", tool.Binary)
+	}
+
+	entry := indexEntry{
+		ArchiveSHA256: archiveSHA256,
+		BinarySHA256:  binarySHA256,
+	}
+	if err := c.writeIndex(tool, version, entry); err != nil {
+		return "", errors.Wrap(err, "failed to write cache index")
+	}
+
+	return binaryPath, nil
+}
+
+func (c *Cache) indexPath(tool Tool, version string) string {
+	return filepath.Join(c.Dir, tool.Name, version+".json")
+}
+
+func (c *Cache) latestPath(tool Tool) string {
+	return filepath.Join(c.Dir, tool.Name, LatestVersion)
+}
+
+func (c *Cache) blobPath(tool Tool, archiveSHA256 string) string {
+	return filepath.Join(c.Dir, "sha256", archiveSHA256, tool.Binary)
+}
+
+func (c *Cache) assetURL(tool Tool, version string, asset string) string {
+	return fmt.Sprintf("%s/%s/releases/download/%s/%s", strings.TrimSuffix(c.BaseURL, "/"), tool.Repo, version, asset)
+}
+
+// lookup returns the cached binary for the tool version if it exists and is intact
+func (c *Cache) lookup(tool Tool, version string) (string, bool) {
+	b, err := ioutil.ReadFile(c.indexPath(tool, version))
+	if err != nil {
+		return "", false
+	}
+
+	entry := indexEntry{}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return "", false
+	}
+
+	binaryPath := c.blobPath(tool, entry.ArchiveSHA256)
+	actualSHA256, err := fileSHA256(binaryPath)
+	if err != nil || actualSHA256 != entry.BinarySHA256 {
+		return "", false
+	}
+
+	return binaryPath, true
+}
+
+func (c *Cache) writeIndex(tool Tool, version string, entry indexEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal index entry")
+	}
+
+	indexPath := c.indexPath(tool, version)
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0755); err != nil {
+		return errors.Wrap(err, "failed to create index dir")
+	}
+
+	return writeFileAtomic(indexPath, b, 0644)
+}
+
+// latestVersion returns the latest release tag, only asking github again once LatestTTL has passed
+func (c *Cache) latestVersion(ctx context.Context, tool Tool) (string, error) {
+	if b, err := ioutil.ReadFile(c.latestPath(tool)); err == nil {
+		entry := latestEntry{}
+		if err := json.Unmarshal(b, &entry); err == nil && entry.Version != "" && c.currentTime().Sub(entry.ResolvedAt) < c.LatestTTL {
+			return entry.Version, nil
+		}
+	}
+
+	version, err := c.resolveLatest(ctx, tool)
+	if err != nil {
+		return "", err
+	}
+
+	if c.LatestTTL > 0 {
+		b, err := json.Marshal(latestEntry{Version: version, ResolvedAt: c.currentTime()})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to marshal latest version")
+		}
+		if err := os.MkdirAll(filepath.Dir(c.latestPath(tool)), 0755); err != nil {
+			return "", errors.Wrap(err, "failed to create index dir")
+		}
+		if err := writeFileAtomic(c.latestPath(tool), b, 0644); err != nil {
+			return "", errors.Wrap(err, "failed to write latest version")
+		}
+	}
+
+	return version, nil
+}
+
+// resolveLatest follows the releases/latest redirect to find the tag of the latest release
+func (c *Cache) resolveLatest(ctx context.Context, tool Tool) (string, error) {
+	url := fmt.Sprintf("%s/%s/releases/latest", strings.TrimSuffix(c.BaseURL, "/"), tool.Repo)
+
+	client := *c.httpClient()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := httpGet(ctx, &client, url)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get %s", url)
+	}
+	defer resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", errors.Errorf("no redirect from %s, unexpected status code %d", url, resp.StatusCode)
+	}
+
+	version := path.Base(location)
+	if version == "" || version == "." || version == "/" {
+		return "", errors.Errorf("unable to parse version from %s", location)
+	}
+
+	return version, nil
+}
+
+// fetchChecksums returns a map of asset name to sha256 from the release checksums file
+func (c *Cache) fetchChecksums(ctx context.Context, tool Tool, version string) (map[string]string, error) {
+	url := c.assetURL(tool, version, tool.ChecksumsAsset(version))
+	resp, err := httpGet(ctx, c.httpClient(), url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to http get %s", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to download from %s, unexpected status code %d", url, resp.StatusCode)
+	}
+
+	checksums := map[string]string{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read checksums")
+	}
+
+	return checksums, nil
+}
+
+func (c *Cache) download(ctx context.Context, url string, w io.Writer) (string, error) {
+	resp, err := httpGet(ctx, c.httpClient(), url)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to http get")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return "", errors.Wrap(err, "failed to save archive file")
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Cache) currentTime() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *Cache) httpClient() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return http.DefaultClient
+}
+
+func httpGet(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create new request")
+	}
+
+	return client.Do(req)
+}
+
+// extractBinary writes the named file from the tar.gz archive to dest and returns its sha256
+func extractBinary(archive io.Reader, name string, dest string) (string, error) {
+	gzf, err := gzip.NewReader(archive)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create gzip reader")
+	}
+
+	tarReader := tar.NewReader(gzf)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read next file")
+		}
+
+		if header.Name != name {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return "", errors.Wrap(err, "failed to create binary dir")
+		}
+
+		tmpFile, err := ioutil.TempFile(filepath.Dir(dest), name)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create temp file")
+		}
+		defer os.RemoveAll(tmpFile.Name())
+		defer tmpFile.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(tmpFile, h), tarReader); err != nil {
+			return "", errors.Wrapf(err, "failed to copy %s binary", name)
+		}
+		if err := tmpFile.Close(); err != nil {
+			return "", errors.Wrap(err, "failed to close temp file")
+		}
+		if err := os.Chmod(tmpFile.Name(), 0755); err != nil {
+			return "", errors.Wrap(err, "failed to chmod")
+		}
+		if err := os.Rename(tmpFile.Name(), dest); err != nil {
+			return "", errors.Wrap(err, "failed to move binary into cache")
+		}
+
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	return "", errors.Errorf("%s binary not found in release", name)
+}
+
+func fileSHA256(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename))
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp file")
+	}
+	defer os.RemoveAll(tmpFile.Name())
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		return errors.Wrap(err, "failed to write temp file")
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temp file")
+	}
+	if err := os.Chmod(tmpFile.Name(), perm); err != nil {
+		return errors.Wrap(err, "failed to chmod")
+	}
+
+	return os.Rename(tmpFile.Name(), filename)
+}
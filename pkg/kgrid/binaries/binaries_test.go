@@ -0,0 +1,153 @@
+package binaries
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTool = Tool{
+	Name:   "kots",
+	Repo:   "replicatedhq/kots",
+	Asset:  "kots_linux_amd64.tar.gz",
+	Binary: "kots",
+	ChecksumsAsset: func(version string) string {
+		return "checksums.txt"
+	},
+}
+
+func makeArchive(t *testing.T, name string, content []byte) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+func newReleaseServer(t *testing.T, archive []byte, checksum string, requests *int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/replicatedhq/kots/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		http.Redirect(w, r, "/replicatedhq/kots/releases/tag/v1.2.3", http.StatusFound)
+	})
+	mux.HandleFunc("/replicatedhq/kots/releases/download/v1.2.3/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		fmt.Fprintf(w, "%s  other.tar.gz\n%s  kots_linux_amd64.tar.gz\n", checksum, checksum)
+	})
+	mux.HandleFunc("/replicatedhq/kots/releases/download/v1.2.3/kots_linux_amd64.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Write(archive)
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_CacheGet(t *testing.T) {
+	req := require.New(t)
+
+	archive := makeArchive(t, "kots", []byte("#!/bin/sh\necho kots\n"))
+	checksum := fmt.Sprintf("%x", sha256.Sum256(archive))
+
+	var requests int32
+	server := newReleaseServer(t, archive, checksum, &requests)
+	defer server.Close()
+
+	c := &Cache{
+		Dir:     t.TempDir(),
+		BaseURL: server.URL,
+	}
+
+	binaryPath, err := c.Get(context.Background(), testTool, "v1.2.3")
+	req.NoError(err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	content, err := ioutil.ReadFile(binaryPath)
+	req.NoError(err)
+	assert.Equal(t, "#!/bin/sh\necho kots\n", string(content))
+
+	// a second get is served from the cache
+	cachedPath, err := c.Get(context.Background(), testTool, "v1.2.3")
+	req.NoError(err)
+	assert.Equal(t, binaryPath, cachedPath)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// latest resolves the version and then uses the cache
+	latestPath, err := c.Get(context.Background(), testTool, LatestVersion)
+	req.NoError(err)
+	assert.Equal(t, binaryPath, latestPath)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// a modified binary is detected and downloaded again
+	req.NoError(ioutil.WriteFile(binaryPath, []byte("tampered"), 0755))
+	_, err = c.Get(context.Background(), testTool, "v1.2.3")
+	req.NoError(err)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&requests))
+}
+
+func Test_CacheGetLatestTTL(t *testing.T) {
+	req := require.New(t)
+
+	archive := makeArchive(t, "kots", []byte("kots"))
+	checksum := fmt.Sprintf("%x", sha256.Sum256(archive))
+
+	var requests int32
+	server := newReleaseServer(t, archive, checksum, &requests)
+	defer server.Close()
+
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	c := &Cache{
+		Dir:       t.TempDir(),
+		BaseURL:   server.URL,
+		LatestTTL: time.Hour,
+		now:       func() time.Time { return now },
+	}
+
+	_, err := c.Get(context.Background(), testTool, LatestVersion)
+	req.NoError(err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// within the ttl, latest doesn't make any requests
+	now = now.Add(59 * time.Minute)
+	_, err = c.Get(context.Background(), testTool, LatestVersion)
+	req.NoError(err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// after it, latest is resolved again
+	now = now.Add(2 * time.Minute)
+	_, err = c.Get(context.Background(), testTool, LatestVersion)
+	req.NoError(err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+}
+
+func Test_CacheGetChecksumMismatch(t *testing.T) {
+	archive := makeArchive(t, "kots", []byte("kots"))
+	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte("something else")))
+
+	var requests int32
+	server := newReleaseServer(t, archive, checksum, &requests)
+	defer server.Close()
+
+	c := &Cache{
+		Dir:     t.TempDir(),
+		BaseURL: server.URL,
+	}
+
+	_, err := c.Get(context.Background(), testTool, "v1.2.3")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
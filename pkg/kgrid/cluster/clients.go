@@ -0,0 +1,155 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Clients holds the clients for a single cluster, built from its kubeconfig
+type Clients struct {
+	RESTConfig *rest.Config
+	Kubernetes kubernetes.Interface
+	Dynamic    dynamic.Interface
+	Discovery  discovery.CachedDiscoveryInterface
+
+	kubeconfig []byte
+}
+
+// ClusterClients caches Clients by grid and cluster name.
+// Cached clients are rebuilt when the cluster's kubeconfig changes.
+type ClusterClients struct {
+	mu      sync.Mutex
+	entries map[string]*clientsEntry
+}
+
+type clientsEntry struct {
+	kubeconfigSHA string
+	clients       *Clients
+}
+
+var defaultClusterClients = NewClusterClients()
+
+func NewClusterClients() *ClusterClients {
+	return &ClusterClients{
+		entries: map[string]*clientsEntry{},
+	}
+}
+
+// GetClients returns the clients for the cluster from the shared cache
+func GetClients(gridName string, c *types.ClusterConfig) (*Clients, error) {
+	return defaultClusterClients.Get(gridName, c)
+}
+
+// Get returns the cached clients for the cluster, building them if needed
+func (cc *ClusterClients) Get(gridName string, c *types.ClusterConfig) (*Clients, error) {
+	key := fmt.Sprintf("%s/%s", gridName, c.Name)
+	kubeconfigSHA := fmt.Sprintf("%x", sha256.Sum256([]byte(c.Kubeconfig)))
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if entry, ok := cc.entries[key]; ok && entry.kubeconfigSHA == kubeconfigSHA {
+		return entry.clients, nil
+	}
+
+	clients, err := NewClients([]byte(c.Kubeconfig))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create clients for cluster %s", key)
+	}
+
+	cc.entries[key] = &clientsEntry{
+		kubeconfigSHA: kubeconfigSHA,
+		clients:       clients,
+	}
+
+	return clients, nil
+}
+
+// Forget removes the cached clients for the cluster
+func (cc *ClusterClients) Forget(gridName string, clusterName string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	delete(cc.entries, fmt.Sprintf("%s/%s", gridName, clusterName))
+}
+
+// NewClients builds clients in memory from kubeconfig bytes
+func NewClients(kubeconfig []byte) (*Clients, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse kubeconfig")
+	}
+	if upgradeExecAPIVersion(config) {
+		kubeconfig, err = clientcmd.Write(*config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to serialize kubeconfig")
+		}
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse kubeconfig")
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create discovery client")
+	}
+
+	return &Clients{
+		RESTConfig: restConfig,
+		Kubernetes: clientset,
+		Dynamic:    dynamicClient,
+		Discovery:  memory.NewMemCacheClient(discoveryClient),
+		kubeconfig: kubeconfig,
+	}, nil
+}
+
+// KubeconfigFile writes the kubeconfig to a temp file that only the current user can read,
+// for external binaries that need a --kubeconfig path.
+// The returned func removes the file.
+func (c *Clients) KubeconfigFile() (string, func(), error) {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to create temp file")
+	}
+	cleanup := func() {
+		os.RemoveAll(f.Name())
+	}
+
+	// TempFile creates the file with 0600 permissions
+	if _, err := f.Write(c.kubeconfig); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, errors.Wrap(err, "failed to close kubeconfig")
+	}
+
+	return f.Name(), cleanup, nil
+}
@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: %s
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`
+
+func Test_ClusterClients(t *testing.T) {
+	req := require.New(t)
+
+	cc := NewClusterClients()
+	c := &types.ClusterConfig{
+		Name:       "a",
+		Kubeconfig: kubeconfig("https://1.2.3.4"),
+	}
+
+	clients, err := cc.Get("grid", c)
+	req.NoError(err)
+	assert.Equal(t, "https://1.2.3.4", clients.RESTConfig.Host)
+
+	// same grid and cluster returns the cached clients
+	cached, err := cc.Get("grid", c)
+	req.NoError(err)
+	assert.Same(t, clients, cached)
+
+	// a different grid with the same cluster name has its own clients
+	other, err := cc.Get("other", c)
+	req.NoError(err)
+	assert.NotSame(t, clients, other)
+
+	// a changed kubeconfig rebuilds the clients
+	c.Kubeconfig = kubeconfig("https://5.6.7.8")
+	rebuilt, err := cc.Get("grid", c)
+	req.NoError(err)
+	assert.NotSame(t, clients, rebuilt)
+	assert.Equal(t, "https://5.6.7.8", rebuilt.RESTConfig.Host)
+
+	cc.Forget("grid", "a")
+	afterForget, err := cc.Get("grid", c)
+	req.NoError(err)
+	assert.NotSame(t, rebuilt, afterForget)
+
+	_, err = cc.Get("grid", &types.ClusterConfig{Name: "b", Kubeconfig: "not a kubeconfig"})
+	req.Error(err)
+}
+
+func Test_KubeconfigFile(t *testing.T) {
+	req := require.New(t)
+
+	clients, err := NewClients([]byte(kubeconfig("https://1.2.3.4")))
+	req.NoError(err)
+
+	path, cleanup, err := clients.KubeconfigFile()
+	req.NoError(err)
+
+	info, err := os.Stat(path)
+	req.NoError(err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	b, err := ioutil.ReadFile(path)
+	req.NoError(err)
+	assert.Equal(t, kubeconfig("https://1.2.3.4"), string(b))
+
+	cleanup()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func kubeconfig(server string) string {
+	return fmt.Sprintf(testKubeconfig, server)
+}
@@ -0,0 +1,184 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	execAPIVersionV1alpha1 = "client.authentication.k8s.io/v1alpha1"
+	execAPIVersionV1beta1  = "client.authentication.k8s.io/v1beta1"
+)
+
+// MergedKubeconfigPath is the kubeconfig that grid contexts are merged into and removed from
+var MergedKubeconfigPath = clientcmd.RecommendedHomeFile
+
+// ContextName is the name of the context, cluster and user for a grid cluster in an exported kubeconfig
+func ContextName(gridName string, clusterName string) string {
+	return fmt.Sprintf("grid-%s-%s", gridName, clusterName)
+}
+
+// Kubeconfig combines the kubeconfigs of the clusters into one, with each cluster's entries named by ContextName.
+// The current context is the first cluster.
+func Kubeconfig(gridName string, clusterConfigs []*types.ClusterConfig) (*clientcmdapi.Config, error) {
+	merged := clientcmdapi.NewConfig()
+
+	for _, c := range clusterConfigs {
+		if c.Kubeconfig == "" {
+			return nil, errors.Errorf("cluster %s has no kubeconfig", c.Name)
+		}
+
+		config, err := clientcmd.Load([]byte(c.Kubeconfig))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse kubeconfig for cluster %s", c.Name)
+		}
+		upgradeExecAPIVersion(config)
+
+		kubeContext, ok := config.Contexts[config.CurrentContext]
+		if !ok {
+			return nil, errors.Errorf("kubeconfig for cluster %s has no current context", c.Name)
+		}
+		kubeCluster, ok := config.Clusters[kubeContext.Cluster]
+		if !ok {
+			return nil, errors.Errorf("kubeconfig for cluster %s is missing cluster %s", c.Name, kubeContext.Cluster)
+		}
+		authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]
+		if !ok {
+			return nil, errors.Errorf("kubeconfig for cluster %s is missing user %s", c.Name, kubeContext.AuthInfo)
+		}
+
+		name := ContextName(gridName, c.Name)
+		merged.Clusters[name] = kubeCluster
+		merged.AuthInfos[name] = authInfo
+		merged.Contexts[name] = &clientcmdapi.Context{
+			Cluster:   name,
+			AuthInfo:  name,
+			Namespace: kubeContext.Namespace,
+		}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = name
+		}
+	}
+
+	return merged, nil
+}
+
+// MergeKubeconfig adds the entries in config to the kubeconfig file at path, replacing entries with the same names.
+// The current context of the file is only set if it was empty.
+// AWS keys are never written to the file, exec plugins use the default credentials or awsProfile instead.
+func MergeKubeconfig(path string, config *clientcmdapi.Config, awsProfile string) error {
+	existing, err := loadKubeconfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	for name, c := range config.Clusters {
+		existing.Clusters[name] = c
+	}
+	for name, a := range config.AuthInfos {
+		existing.AuthInfos[name] = withoutAWSCredentials(a, awsProfile)
+	}
+	for name, c := range config.Contexts {
+		existing.Contexts[name] = c
+	}
+	if existing.CurrentContext == "" {
+		existing.CurrentContext = config.CurrentContext
+	}
+
+	if err := clientcmd.WriteToFile(*existing, path); err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	return nil
+}
+
+// RemoveFromKubeconfig removes the named contexts, and the clusters and users with the same names, from the kubeconfig file at path.
+// The file isn't written if none of them are in it.
+func RemoveFromKubeconfig(path string, names ...string) error {
+	existing, err := loadKubeconfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	removed := false
+	for _, name := range names {
+		if _, ok := existing.Contexts[name]; ok {
+			delete(existing.Contexts, name)
+			removed = true
+		}
+		if _, ok := existing.Clusters[name]; ok {
+			delete(existing.Clusters, name)
+			removed = true
+		}
+		if _, ok := existing.AuthInfos[name]; ok {
+			delete(existing.AuthInfos, name)
+			removed = true
+		}
+		if existing.CurrentContext == name {
+			existing.CurrentContext = ""
+		}
+	}
+	if !removed {
+		return nil
+	}
+
+	if err := clientcmd.WriteToFile(*existing, path); err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+
+	return nil
+}
+
+// withoutAWSCredentials returns a copy of authInfo with AWS keys removed from its exec env,
+// and AWS_PROFILE set to awsProfile if it isn't empty
+func withoutAWSCredentials(authInfo *clientcmdapi.AuthInfo, awsProfile string) *clientcmdapi.AuthInfo {
+	if authInfo.Exec == nil {
+		return authInfo
+	}
+
+	stripped := authInfo.DeepCopy()
+	env := []clientcmdapi.ExecEnvVar{}
+	for _, e := range stripped.Exec.Env {
+		switch e.Name {
+		case "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE":
+			continue
+		}
+		env = append(env, e)
+	}
+	if awsProfile != "" {
+		env = append(env, clientcmdapi.ExecEnvVar{Name: "AWS_PROFILE", Value: awsProfile})
+	}
+	stripped.Exec.Env = env
+
+	return stripped
+}
+
+func loadKubeconfigFile(path string) (*clientcmdapi.Config, error) {
+	config, err := clientcmd.LoadFromFile(path)
+	if os.IsNotExist(errors.Cause(err)) {
+		return clientcmdapi.NewConfig(), nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to load kubeconfig")
+	}
+
+	return config, nil
+}
+
+// upgradeExecAPIVersion moves exec credential plugins off v1alpha1, which current clients no longer support.
+// aws eks get-token responds with the version the client asks for.
+// It returns true if anything was changed.
+func upgradeExecAPIVersion(config *clientcmdapi.Config) bool {
+	upgraded := false
+	for _, authInfo := range config.AuthInfos {
+		if authInfo.Exec != nil && authInfo.Exec.APIVersion == execAPIVersionV1alpha1 {
+			authInfo.Exec.APIVersion = execAPIVersionV1beta1
+			upgraded = true
+		}
+	}
+	return upgraded
+}
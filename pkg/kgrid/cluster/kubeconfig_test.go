@@ -0,0 +1,152 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const testEKSKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    server: https://eks.example.com
+  name: kubernetes
+contexts:
+- context:
+    cluster: kubernetes
+    user: aws
+  name: aws
+current-context: aws
+kind: Config
+users:
+- name: aws
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1alpha1
+      command: aws
+      args:
+      - eks
+      - get-token
+`
+
+const testEKSKubeconfigWithKeys = `apiVersion: v1
+clusters:
+- cluster:
+    server: https://eks.example.com
+  name: kubernetes
+contexts:
+- context:
+    cluster: kubernetes
+    user: aws
+  name: aws
+current-context: aws
+kind: Config
+users:
+- name: aws
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args:
+      - eks
+      - get-token
+      env:
+      - name: AWS_ACCESS_KEY_ID
+        value: AKIAEXAMPLE
+      - name: AWS_SECRET_ACCESS_KEY
+        value: secret
+      - name: AWS_DEFAULT_OUTPUT
+        value: json
+`
+
+func Test_Kubeconfig(t *testing.T) {
+	req := require.New(t)
+
+	kubeconfig, err := Kubeconfig("grid", []*types.ClusterConfig{
+		{Name: "a", Kubeconfig: testEKSKubeconfig},
+		{Name: "b", Kubeconfig: kubeconfig("https://1.2.3.4")},
+	})
+	req.NoError(err)
+
+	assert.Equal(t, "grid-grid-a", kubeconfig.CurrentContext)
+	req.Len(kubeconfig.Contexts, 2)
+	assert.Equal(t, "grid-grid-b", kubeconfig.Contexts["grid-grid-b"].Cluster)
+	assert.Equal(t, "grid-grid-b", kubeconfig.Contexts["grid-grid-b"].AuthInfo)
+	assert.Equal(t, "https://eks.example.com", kubeconfig.Clusters["grid-grid-a"].Server)
+	assert.Equal(t, "https://1.2.3.4", kubeconfig.Clusters["grid-grid-b"].Server)
+	assert.Equal(t, "abc", kubeconfig.AuthInfos["grid-grid-b"].Token)
+
+	// v1alpha1 exec plugins are no longer supported by clients
+	assert.Equal(t, execAPIVersionV1beta1, kubeconfig.AuthInfos["grid-grid-a"].Exec.APIVersion)
+
+	clients, err := NewClients([]byte(testEKSKubeconfig))
+	req.NoError(err)
+	assert.Equal(t, execAPIVersionV1beta1, clients.RESTConfig.ExecProvider.APIVersion)
+
+	_, err = Kubeconfig("grid", []*types.ClusterConfig{{Name: "creating"}})
+	assert.EqualError(t, err, "cluster creating has no kubeconfig")
+}
+
+func Test_MergeKubeconfig(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, ".kube", "config")
+
+	// an existing context that isn't part of the grid
+	existing := clientcmdapi.NewConfig()
+	existing.Clusters["mine"] = &clientcmdapi.Cluster{Server: "https://mine"}
+	existing.AuthInfos["mine"] = &clientcmdapi.AuthInfo{Token: "mine"}
+	existing.Contexts["mine"] = &clientcmdapi.Context{Cluster: "mine", AuthInfo: "mine"}
+	existing.CurrentContext = "mine"
+	req.NoError(clientcmd.WriteToFile(*existing, path))
+
+	kubeconfig, err := Kubeconfig("grid", []*types.ClusterConfig{
+		{Name: "a", Kubeconfig: kubeconfig("https://1.2.3.4")},
+		{Name: "eks", Kubeconfig: testEKSKubeconfigWithKeys},
+	})
+	req.NoError(err)
+
+	req.NoError(MergeKubeconfig(path, kubeconfig, "dev"))
+
+	merged, err := clientcmd.LoadFromFile(path)
+	req.NoError(err)
+	assert.Equal(t, "mine", merged.CurrentContext)
+	assert.Len(t, merged.Contexts, 3)
+	assert.Equal(t, "https://1.2.3.4", merged.Clusters[ContextName("grid", "a")].Server)
+	assert.Equal(t, "abc", merged.AuthInfos[ContextName("grid", "a")].Token)
+
+	// the keys stay in the grid config, and the merged context uses the profile instead
+	assert.Equal(t, []clientcmdapi.ExecEnvVar{
+		{Name: "AWS_DEFAULT_OUTPUT", Value: "json"},
+		{Name: "AWS_PROFILE", Value: "dev"},
+	}, merged.AuthInfos[ContextName("grid", "eks")].Exec.Env)
+	assert.Len(t, kubeconfig.AuthInfos[ContextName("grid", "eks")].Exec.Env, 3)
+
+	info, err := os.Stat(path)
+	req.NoError(err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	req.NoError(RemoveFromKubeconfig(path, ContextName("grid", "a"), ContextName("grid", "eks")))
+
+	removed, err := clientcmd.LoadFromFile(path)
+	req.NoError(err)
+	assert.Equal(t, "mine", removed.CurrentContext)
+	assert.Len(t, removed.Contexts, 1)
+	assert.Len(t, removed.Clusters, 1)
+	assert.Len(t, removed.AuthInfos, 1)
+
+	// removing from a kubeconfig that doesn't exist does nothing
+	req.NoError(RemoveFromKubeconfig(filepath.Join(tmpDir, "missing"), ContextName("grid", "a")))
+	_, err = os.Stat(filepath.Join(tmpDir, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
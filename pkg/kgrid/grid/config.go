@@ -1,94 +1,187 @@
 package grid
 
 import (
-	"io/ioutil"
-	"os"
-	"path/filepath"
 	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
-	"sigs.k8s.io/yaml"
 )
 
 var (
 	l sync.Mutex
 )
 
-func lockConfig() {
+// lockConfig locks the config against other goroutines and other processes.
+// The returned func releases both locks.
+func lockConfig(store ConfigStore) (func(), error) {
 	l.Lock()
+
+	unlock, err := store.Lock()
+	if err != nil {
+		l.Unlock()
+		return nil, errors.Wrap(err, "failed to lock config")
+	}
+
+	return func() {
+		unlock()
+		l.Unlock()
+	}, nil
 }
 
-func unlockConfig() {
-	l.Unlock()
+func loadConfig(store ConfigStore) (*types.GridsConfig, error) {
+	return store.Load()
 }
 
-func loadConfig(path string) (*types.GridsConfig, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		cfg := types.GridsConfig{
-			GridConfigs: []*types.GridConfig{},
-		}
-		b, err := yaml.Marshal(cfg)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to marshal empty config")
-		}
+func saveConfig(cfg *types.GridsConfig, store ConfigStore) error {
+	return store.Save(cfg)
+}
 
-		if err := os.MkdirAll(filepath.Dir(path), 0744); err != nil {
-			return nil, errors.Wrap(err, "failed to create config dir")
-		}
+func addClusterToConfig(store ConfigStore, gridName string, clusterConfig *types.ClusterConfig) error {
+	unlock, err := lockConfig(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, err := loadConfig(store)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
 
-		if err := ioutil.WriteFile(path, b, 0644); err != nil {
-			return nil, errors.Wrap(err, "failed to write config file")
+	for _, gridConfig := range c.GridConfigs {
+		if gridConfig.Name == gridName {
+			gridConfig.ClusterConfigs = append(gridConfig.ClusterConfigs, clusterConfig)
 		}
+	}
 
-		return &cfg, nil
+	if err := saveConfig(c, store); err != nil {
+		return errors.Wrap(err, "error saving config")
 	}
 
-	b, err := ioutil.ReadFile(path)
+	return nil
+}
+
+// updateClusterInConfig replaces the stored config for the cluster with the same name
+func updateClusterInConfig(store ConfigStore, gridName string, clusterConfig *types.ClusterConfig) error {
+	unlock, err := lockConfig(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, err := loadConfig(store)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to read config file")
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	found := false
+	for _, gridConfig := range c.GridConfigs {
+		if gridConfig.Name != gridName {
+			continue
+		}
+		for i, existing := range gridConfig.ClusterConfigs {
+			if existing.Name == clusterConfig.Name {
+				gridConfig.ClusterConfigs[i] = clusterConfig
+				found = true
+			}
+		}
+	}
+	if !found {
+		return errors.Errorf("cluster %s not found in grid %s", clusterConfig.Name, gridName)
 	}
 
-	cfg := types.GridsConfig{}
-	if err := yaml.Unmarshal(b, &cfg); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal config")
+	if err := saveConfig(c, store); err != nil {
+		return errors.Wrap(err, "error saving config")
 	}
 
-	return &cfg, nil
+	return nil
 }
 
-func saveConfig(cfg *types.GridsConfig, path string) error {
-	b, err := yaml.Marshal(cfg)
+func removeGridFromConfig(name string, store ConfigStore) error {
+	unlock, err := lockConfig(store)
 	if err != nil {
-		return errors.Wrap(err, "failed to marshal config")
+		return err
 	}
+	defer unlock()
 
-	if err := ioutil.WriteFile(path, b, 0644); err != nil {
-		return errors.Wrap(err, "failed to write config file")
+	oldCfg, err := loadConfig(store)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	newCfg := types.GridsConfig{}
+	for _, g := range oldCfg.GridConfigs {
+		if g.Name == name {
+			continue
+		}
+
+		newCfg.GridConfigs = append(newCfg.GridConfigs, g)
+	}
+
+	if err := saveConfig(&newCfg, store); err != nil {
+		return errors.Wrap(err, "failed to save config")
 	}
 
 	return nil
 }
 
-func removeGridFromConfig(name string, path string) error {
-	lockConfig()
-	defer unlockConfig()
+// removeGridIfEmpty removes the grid from the config if it has no clusters
+func removeGridIfEmpty(name string, store ConfigStore) error {
+	unlock, err := lockConfig(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	oldCfg, err := loadConfig(path)
+	oldCfg, err := loadConfig(store)
 	if err != nil {
 		return errors.Wrap(err, "failed to load config")
 	}
 
 	newCfg := types.GridsConfig{}
 	for _, g := range oldCfg.GridConfigs {
-		if g.Name == name {
+		if g.Name == name && len(g.ClusterConfigs) == 0 {
 			continue
 		}
 
 		newCfg.GridConfigs = append(newCfg.GridConfigs, g)
 	}
 
-	if err := saveConfig(&newCfg, path); err != nil {
+	if err := saveConfig(&newCfg, store); err != nil {
+		return errors.Wrap(err, "failed to save config")
+	}
+
+	return nil
+}
+
+func removeClusterFromConfig(gridName string, clusterName string, store ConfigStore) error {
+	unlock, err := lockConfig(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, err := loadConfig(store)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	for _, g := range c.GridConfigs {
+		if g.Name != gridName {
+			continue
+		}
+
+		clusterConfigs := []*types.ClusterConfig{}
+		for _, clusterConfig := range g.ClusterConfigs {
+			if clusterConfig.Name == clusterName {
+				continue
+			}
+			clusterConfigs = append(clusterConfigs, clusterConfig)
+		}
+		g.ClusterConfigs = clusterConfigs
+	}
+
+	if err := saveConfig(c, store); err != nil {
 		return errors.Wrap(err, "failed to save config")
 	}
 
@@ -48,6 +48,49 @@ func Test_loadConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "partially created cluster",
+			data: `grids:
+  - clusters:
+    - name: grid-abc
+      provider: aws
+      region: us-west-1
+      accessKeyId:
+        valueFrom:
+          osEnv: AWS_ACCESS_KEY_ID
+      vpc:
+        id: vpc-123
+        roleArn: arn:aws:iam::123:role/kgrid
+      nodeGroupName: grid-abc
+      creating: true
+      completedSteps:
+      - vpc
+    name: s`,
+			expected: &types.GridsConfig{
+				GridConfigs: []*types.GridConfig{
+					{
+						Name: "s",
+						ClusterConfigs: []*types.ClusterConfig{
+							{
+								Name:     "grid-abc",
+								Provider: "aws",
+								Region:   "us-west-1",
+								AccessKeyID: &types.ValueOrValueFrom{
+									ValueFrom: &types.ValueFrom{OSEnv: "AWS_ACCESS_KEY_ID"},
+								},
+								VPC: &types.AWSVPC{
+									ID:      "vpc-123",
+									RoleArn: "arn:aws:iam::123:role/kgrid",
+								},
+								NodeGroupName:  "grid-abc",
+								Creating:       true,
+								CompletedSteps: []types.ClusterCreateStep{types.ClusterCreateStepVPC},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -61,7 +104,7 @@ func Test_loadConfig(t *testing.T) {
 			err = ioutil.WriteFile(tmpFile.Name(), []byte(test.data), 0644)
 			req.NoError(err)
 
-			actual, err := loadConfig(tmpFile.Name())
+			actual, err := loadConfig(NewFileStore(tmpFile.Name()))
 			req.NoError(err)
 
 			assert.Equal(t, test.expected, actual)
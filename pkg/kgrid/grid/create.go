@@ -4,96 +4,144 @@ import (
 	"context"
 	"crypto/md5"
 	"fmt"
-	"reflect"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/pkg/errors"
 	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/kubectl"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// createConcurrency is the max number of clusters created at the same time
+	createConcurrency = 8
+
+	// rollbackTimeout bounds deleting the clusters of a failed create, which runs after ctx may have been cancelled
+	rollbackTimeout = 30 * time.Minute
+)
+
+// CreateOptions controls what happens to clusters whose create doesn't finish
+type CreateOptions struct {
+	// KeepOnFailure leaves unfinished clusters checkpointed in the config for Resume, instead of deleting them
+	KeepOnFailure bool
+}
+
 // Create will create the grid defined in the gridSpec
 // the name of the grid will be the name in the metadata.name field
 // This function is synchronous and will not return until all clusters are ready
-func Create(configFilePath string, g *types.Grid, log logger.Logger) error {
-	completed := map[int]bool{}
-	completedChans := make([]chan string, len(g.Spec.Clusters))
-	for i := range g.Spec.Clusters {
-		completedChans[i] = make(chan string)
-		completed[i] = false
+// If a cluster fails or ctx is cancelled, clusters that didn't finish are rolled back: whatever was created for them
+// is deleted and they are removed from the config, unless opts.KeepOnFailure is set.
+// Finished clusters are kept, and the grid is removed only if it's empty.
+func Create(ctx context.Context, store ConfigStore, g *types.Grid, opts CreateOptions, log logger.Logger) error {
+	if err := addGridToConfig(store, g.Name); err != nil {
+		return errors.Wrap(err, "failed to add grid to config file")
 	}
 
-	if err := addGridToConfig(configFilePath, g.Name); err != nil {
-		return errors.Wrap(err, "failed to add grid to config file")
+	tasks := []parallel.Task[struct{}]{}
+	for _, cluster := range g.Spec.Clusters {
+		cluster := cluster
+		tasks = append(tasks, func(ctx context.Context) (struct{}, error) {
+			clusterLog := log.ForCluster(cluster.GetNameForLogging())
+			if err := createClusterFunc(ctx, g.Name, cluster, store, clusterLog); err != nil {
+				clusterLog.Error(err)
+				return struct{}{}, err
+			}
+			return struct{}{}, nil
+		})
 	}
 
-	// start listening for completed events
-	finished := make(chan bool)
 	createErrors := []error{}
-	go func() {
-		cases := make([]reflect.SelectCase, len(completedChans))
-		for i, ch := range completedChans {
-			cases[i] = reflect.SelectCase{
-				Dir:  reflect.SelectRecv,
-				Chan: reflect.ValueOf(ch),
-			}
+	for _, result := range parallel.Run(ctx, createConcurrency, tasks) {
+		if result.Err == nil {
+			continue
 		}
 
-		for {
-			i, completedErr, ok := reflect.Select(cases)
-			if ok {
-				clusterName := g.Spec.Clusters[i].GetNameForLogging()
-				if clusterName == "" {
-					clusterName = fmt.Sprintf("%d", i)
-				}
+		clusterName := g.Spec.Clusters[result.Index].GetNameForLogging()
+		if clusterName == "" {
+			clusterName = fmt.Sprintf("%d", result.Index)
+		}
+		createErrors = append(createErrors, errors.Wrapf(result.Err, "create cluster %s", clusterName))
+	}
+
+	if (len(createErrors) > 0 || ctx.Err() != nil) && !opts.KeepOnFailure {
+		if err := rollbackUnfinishedClusters(g.Name, store, log); err != nil {
+			createErrors = append(createErrors, errors.Wrap(err, "failed to roll back create"))
+		}
+		if err := removeGridIfEmpty(g.Name, store); err != nil {
+			createErrors = append(createErrors, errors.Wrap(err, "failed to remove empty grid from config"))
+		}
+	}
+	if ctx.Err() != nil {
+		createErrors = append(createErrors, ctx.Err())
+	}
 
-				if completedErr.String() != "" {
-					err := errors.Wrapf(errors.New(completedErr.String()), "create cluster %s", clusterName)
-					createErrors = append(createErrors, err)
-				}
+	if len(createErrors) > 0 {
+		return &kerrors.MultiError{Errors: createErrors}
+	}
 
-				completed[i] = true
-			}
+	return nil
+}
 
-			allCompleted := true
-			for _, v := range completed {
-				if !v {
-					allCompleted = false
-				}
-			}
+// rollbackUnfinishedClusters deletes the clusters in the grid that are still being created, and removes them from the config.
+// It doesn't use the create's context, so a cancelled create is still cleaned up.
+func rollbackUnfinishedClusters(gridName string, store ConfigStore, log logger.Logger) error {
+	gridConfig, err := getGridConfig(store, gridName)
+	if err != nil {
+		return err
+	}
 
-			if allCompleted {
-				finished <- true
-				return
-			}
+	clusterConfigs := []*types.ClusterConfig{}
+	for _, c := range gridConfig.ClusterConfigs {
+		if c.Creating {
+			clusterConfigs = append(clusterConfigs, c)
 		}
-	}()
-
-	// start each
-	for i, cluster := range g.Spec.Clusters {
-		go createCluster(g.Name, cluster, completedChans[i], configFilePath, log)
 	}
 
-	// wait for all channels to be closed
-	<-finished
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
 
-	if len(createErrors) > 0 {
-		return &kerrors.MultiError{Errors: createErrors}
+	tasks := []parallel.Task[struct{}]{}
+	for _, c := range clusterConfigs {
+		c := c
+		tasks = append(tasks, func(ctx context.Context) (struct{}, error) {
+			clusterLog := log.ForCluster(c.Name)
+			clusterLog.Info("Create did not finish, deleting cluster %s", c.Name)
+			if err := deleteClusterFunc(ctx, c, clusterLog); err != nil {
+				return struct{}{}, err
+			}
+			return struct{}{}, removeClusterFromConfig(gridName, c.Name, store)
+		})
+	}
+
+	rollbackErrors := []error{}
+	for _, result := range parallel.Run(ctx, deleteConcurrency, tasks) {
+		if result.Err != nil {
+			rollbackErrors = append(rollbackErrors, errors.Wrapf(result.Err, "delete cluster %s", clusterConfigs[result.Index].Name))
+		}
+	}
+	if len(rollbackErrors) > 0 {
+		return &kerrors.MultiError{Errors: rollbackErrors}
 	}
 
 	return nil
 }
 
-func addGridToConfig(configFilePath string, name string) error {
-	lockConfig()
-	defer unlockConfig()
-	c, err := loadConfig(configFilePath)
+func addGridToConfig(store ConfigStore, name string) error {
+	unlock, err := lockConfig(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	c, err := loadConfig(store)
 	if err != nil {
 		return errors.Wrap(err, "failed to load config")
 	}
@@ -115,59 +163,51 @@ func addGridToConfig(configFilePath string, name string) error {
 	}
 	c.GridConfigs = append(c.GridConfigs, &gridConfig)
 
-	if err := saveConfig(c, configFilePath); err != nil {
+	if err := saveConfig(c, store); err != nil {
 		return errors.Wrap(err, "failed to save config")
 	}
 
 	return nil
 }
 
+// createClusterFunc creates a cluster in the grid, tests replace it
+var createClusterFunc = createCluster
+
 // createCluster will create the cluster synchronously
-// when it's completed, it will return the error or "" as a string on the channel
-func createCluster(gridName string, cluster *types.ClusterSpec, completedCh chan string, configFilePath string, log logger.Logger) {
+func createCluster(ctx context.Context, gridName string, cluster *types.ClusterSpec, store ConfigStore, log logger.Logger) error {
 	if cluster.EKS != nil {
-		createEKSCluster(gridName, cluster.EKS, completedCh, configFilePath, log)
-		return
+		return createEKSCluster(ctx, gridName, cluster.EKS, store, log)
 	}
 
-	completedCh <- "unknown cluster"
+	return errors.New("unknown cluster")
 }
 
-func createEKSCluster(gridName string, eksCluster *types.EKSSpec, completedCh chan string, configFilePath string, log logger.Logger) {
+func createEKSCluster(ctx context.Context, gridName string, eksCluster *types.EKSSpec, store ConfigStore, log logger.Logger) error {
 	if eksCluster.ExistingCluster != nil {
-		connectExistingEKSCluster(gridName, eksCluster.ExistingCluster, completedCh, configFilePath, log)
-		return
+		return connectExistingEKSCluster(ctx, gridName, eksCluster.ExistingCluster, store, log)
 	} else if eksCluster.NewCluster != nil {
-		createNewEKSCluter(gridName, eksCluster.NewCluster, completedCh, configFilePath, log)
-		return
+		return createNewEKSCluter(ctx, gridName, eksCluster.NewCluster, store, log)
 	}
 
-	completedCh <- "eks cluster must have new or existing"
+	return errors.New("eks cluster must have new or existing")
 }
 
-func connectExistingEKSCluster(gridName string, existingEKSCluster *types.EKSExistingClusterSpec, completedCh chan string, configFilePath string, log logger.Logger) {
+func connectExistingEKSCluster(ctx context.Context, gridName string, existingEKSCluster *types.EKSExistingClusterSpec, store ConfigStore, log logger.Logger) error {
 	accessKeyID, err := existingEKSCluster.AccessKeyID.String()
 	if err != nil {
-		completedCh <- fmt.Sprintf("failed to read access key id: %s", err.Error())
+		return errors.Wrap(err, "failed to read access key id")
 	}
 	secretAccessKey, err := existingEKSCluster.SecretAccessKey.String()
 	if err != nil {
-		completedCh <- fmt.Sprintf("failed to read secret access key: %s", err.Error())
+		return errors.Wrap(err, "failed to read secret access key")
 	}
 
-	kubeConfig, err := GetEKSClusterKubeConfig(existingEKSCluster.Region, accessKeyID, secretAccessKey, existingEKSCluster.ClusterName)
+	kubeConfig, err := GetEKSClusterKubeConfig(ctx, existingEKSCluster.Region, accessKeyID, secretAccessKey, existingEKSCluster.ClusterName)
 	if err != nil {
-		completedCh <- fmt.Sprintf("failed to get kubeconfig from eks cluster: %s", err.Error())
-	}
-
-	lockConfig()
-	defer unlockConfig()
-	c, err := loadConfig(configFilePath)
-	if err != nil {
-		completedCh <- fmt.Sprintf("failed to load config: %s", err.Error())
-		return
+		return errors.Wrap(err, "failed to get kubeconfig from eks cluster")
 	}
 
+	now := metav1.Now()
 	clusterConfig := types.ClusterConfig{
 		Name: existingEKSCluster.ClusterName,
 		// Description:
@@ -176,137 +216,216 @@ func connectExistingEKSCluster(gridName string, existingEKSCluster *types.EKSExi
 		Region:     existingEKSCluster.Region,
 		Version:    "", // TODO
 		Kubeconfig: kubeConfig,
-	}
+		CreatedAt:  &now,
 
-	for _, gridConfig := range c.GridConfigs {
-		if gridConfig.Name == gridName {
-			gridConfig.ClusterConfigs = append(gridConfig.ClusterConfigs, &clusterConfig)
-		}
+		AccessKeyID:     &existingEKSCluster.AccessKeyID,
+		SecretAccessKey: &existingEKSCluster.SecretAccessKey,
 	}
-	if err := saveConfig(c, configFilePath); err != nil {
-		completedCh <- fmt.Sprintf("error saving config: %s", err.Error())
+
+	if err := addClusterToConfig(store, gridName, &clusterConfig); err != nil {
+		return errors.Wrap(err, "failed to add cluster to config")
 	}
 
-	completedCh <- ""
+	return nil
 }
 
 // createNewEKSCluster will create a complete, ready to use EKS cluster with all
-// security groups, vpcs, node pools, and everything else
-func createNewEKSCluter(gridName string, newEKSCluster *types.EKSNewClusterSpec, completedCh chan string, configFilePath string, log logger.Logger) {
+// security groups, vpcs, node pools, and everything else.
+// The cluster is added to the config before anything is created, so a create that doesn't finish can be rolled back.
+func createNewEKSCluter(ctx context.Context, gridName string, newEKSCluster *types.EKSNewClusterSpec, store ConfigStore, log logger.Logger) error {
 	newEKSCluster.Name = generateClusterName()
 
 	log.Info("Creating EKS cluster with all required dependencies with name %s", newEKSCluster.Name)
 
-	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(newEKSCluster.Region))
+	now := metav1.Now()
+	clusterConfig := &types.ClusterConfig{
+		Name:        newEKSCluster.Name,
+		Description: newEKSCluster.Description,
+		Provider:    "aws",
+		IsExisting:  false,
+		Region:      newEKSCluster.Region,
+		Version:     newEKSCluster.Version,
+		CreatedAt:   &now,
+
+		AccessKeyID:     &newEKSCluster.AccessKeyID,
+		SecretAccessKey: &newEKSCluster.SecretAccessKey,
+		NodeGroupName:   newEKSCluster.Name,
+		Creating:        true,
+	}
+
+	if err := addClusterToConfig(store, gridName, clusterConfig); err != nil {
+		return errors.Wrap(err, "failed to add cluster to config")
+	}
+
+	return buildNewEKSCluster(ctx, gridName, clusterConfig, store, log)
+}
+
+// buildNewEKSCluster runs the create steps that haven't completed yet for a cluster in the config
+func buildNewEKSCluster(ctx context.Context, gridName string, clusterConfig *types.ClusterConfig, store ConfigStore, log logger.Logger) error {
+	if clusterConfig.AccessKeyID == nil || clusterConfig.SecretAccessKey == nil {
+		return errors.New("cluster config has no aws credentials")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(clusterConfig.Region))
 	if err != nil {
-		completedCh <- fmt.Sprintf("error loading aws config: %s", err.Error())
-		return
+		return errors.Wrap(err, "error loading aws config")
 	}
 
-	accessKeyID, err := newEKSCluster.AccessKeyID.String()
+	accessKeyID, err := clusterConfig.AccessKeyID.String()
 	if err != nil {
-		completedCh <- fmt.Sprintf("error retreiving access key id: %s", err.Error())
-		return
+		return errors.Wrap(err, "error retreiving access key id")
 	}
-	secretAccessKey, err := newEKSCluster.SecretAccessKey.String()
+	secretAccessKey, err := clusterConfig.SecretAccessKey.String()
 	if err != nil {
-		completedCh <- fmt.Sprintf("error retrieving secret access key: %s", err.Error())
-		return
+		return errors.Wrap(err, "error retrieving secret access key")
 	}
 
 	cfg.Credentials = credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
 
-	log.Info("Creating VPC for EKS cluster")
-	vpc, err := ensureEKSClusterVPC(cfg)
-	if err != nil {
-		completedCh <- fmt.Sprintf("failed to create EKS cluster vpc: %s", err.Error())
-		return
+	return createNewEKSClusterResources(ctx, cfg, gridName, clusterConfig, accessKeyID, secretAccessKey, store, log)
+}
+
+func createNewEKSClusterResources(ctx context.Context, cfg aws.Config, gridName string, clusterConfig *types.ClusterConfig, accessKeyID string, secretAccessKey string, store ConfigStore, log logger.Logger) error {
+	newEKSCluster := &types.EKSNewClusterSpec{
+		Name:    clusterConfig.Name,
+		Version: clusterConfig.Version,
+		Region:  clusterConfig.Region,
 	}
 
-	log.Info("Creating EKS Cluster Control Plane")
-	cluster, err := ensureEKSCluterControlPlane(cfg, newEKSCluster, newEKSCluster.Name, vpc)
-	if err != nil {
-		if !strings.Contains(err.Error(), "Cluster already exists with name") {
-			completedCh <- fmt.Sprintf("failed to create eks cluster control plane: %s", err.Error())
-			return
+	if !clusterConfig.HasCompletedStep(types.ClusterCreateStepVPC) {
+		log.Info("Creating VPC for EKS cluster")
+		vpc, err := ensureEKSClusterVPC(ctx, cfg)
+		if err != nil {
+			return errors.Wrap(err, "failed to create EKS cluster vpc")
+		}
+
+		clusterConfig.VPC = vpc
+		if err := completeCreateStep(store, gridName, clusterConfig, types.ClusterCreateStepVPC); err != nil {
+			return err
 		}
 	}
 
-	log.Info("Waiting for EKS Cluster Control Plane to be ready (this can take a while, 15 minutes is not unusual)")
-	if err := waitForClusterToBeActive(newEKSCluster, accessKeyID, secretAccessKey, newEKSCluster.Name); err != nil {
-		completedCh <- fmt.Sprintf("cluster did not become ready")
-		return
+	if !clusterConfig.HasCompletedStep(types.ClusterCreateStepControlPlane) {
+		log.Info("Creating EKS Cluster Control Plane")
+		_, err := ensureEKSCluterControlPlane(ctx, cfg, newEKSCluster, clusterConfig.Name, clusterConfig.VPC)
+		if err != nil {
+			if !strings.Contains(err.Error(), "Cluster already exists with name") {
+				return errors.Wrap(err, "failed to create eks cluster control plane")
+			}
+		}
+
+		log.Info("Waiting for EKS Cluster Control Plane to be ready (this can take a while, 15 minutes is not unusual)")
+		if err := waitForClusterToBeActive(ctx, newEKSCluster, accessKeyID, secretAccessKey, clusterConfig.Name); err != nil {
+			return errors.Wrap(err, "cluster did not become ready")
+		}
+
+		controlPlane, err := describeEKSCluster(ctx, cfg, clusterConfig.Name)
+		if err != nil {
+			return errors.Wrap(err, "failed to get eks cluster control plane")
+		}
+
+		clusterConfig.ControlPlaneARN = aws.ToString(controlPlane.Arn)
+		if err := completeCreateStep(store, gridName, clusterConfig, types.ClusterCreateStepControlPlane); err != nil {
+			return err
+		}
 	}
 
-	log.Info("Creating EKS Cluster Node Group")
-	nodeGroup, err := ensureEKSClusterNodeGroup(cfg, cluster, newEKSCluster.Name, vpc)
-	if err != nil {
-		if !strings.Contains(err.Error(), "NodeGroup already exists") {
-			completedCh <- fmt.Sprintf("failed to create eks cluster node pool: %s", err.Error())
-			return
+	if !clusterConfig.HasCompletedStep(types.ClusterCreateStepNodeGroup) {
+		log.Info("Creating EKS Cluster Node Group")
+		_, err := ensureEKSClusterNodeGroup(ctx, cfg, clusterConfig.Name, clusterConfig.NodeGroupName, clusterConfig.VPC)
+		if err != nil {
+			if !strings.Contains(err.Error(), "NodeGroup already exists") {
+				return errors.Wrap(err, "failed to create eks cluster node pool")
+			}
+		}
+
+		nodeGroup, err := describeEKSNodeGroup(ctx, cfg, clusterConfig.Name, clusterConfig.NodeGroupName)
+		if err != nil {
+			return errors.Wrap(err, "failed to get eks cluster node pool")
+		}
+
+		clusterConfig.NodeGroupARN = aws.ToString(nodeGroup.NodegroupArn)
+		if err := completeCreateStep(store, gridName, clusterConfig, types.ClusterCreateStepNodeGroup); err != nil {
+			return err
 		}
 	}
 
-	kubeConfig, err := GetEKSClusterKubeConfig(newEKSCluster.Region, accessKeyID, secretAccessKey, newEKSCluster.Name)
+	if !clusterConfig.HasCompletedStep(types.ClusterCreateStepKubeconfig) {
+		kubeConfig, err := GetEKSClusterKubeConfig(ctx, clusterConfig.Region, accessKeyID, secretAccessKey, clusterConfig.Name)
+		if err != nil {
+			return errors.Wrap(err, "failed to get kubeconfig from eks cluster")
+		}
+
+		clusterConfig.Kubeconfig = kubeConfig
+		if err := completeCreateStep(store, gridName, clusterConfig, types.ClusterCreateStepKubeconfig); err != nil {
+			return err
+		}
+	}
+
+	clients, err := cluster.GetClients(gridName, clusterConfig)
 	if err != nil {
-		completedCh <- fmt.Sprintf("failed to get kubeconfig from eks cluster: %s", err.Error())
-		return
+		return errors.Wrap(err, "failed to get cluster clients")
 	}
 
-	clusterConfig := types.ClusterConfig{
-		Name:        newEKSCluster.Name,
-		Description: newEKSCluster.Description,
-		Provider:    "aws",
-		IsExisting:  false,
-		Region:      newEKSCluster.Region,
-		Version:     newEKSCluster.Version,
-		Kubeconfig:  kubeConfig,
+	if err := waitForAPIServer(ctx, clients); err != nil {
+		return errors.Wrap(err, "failed to wait for API server")
 	}
 
-	func() {
-		lockConfig()
-		defer unlockConfig()
-		c, err := loadConfig(configFilePath)
-		if err != nil {
-			completedCh <- fmt.Sprintf("failed to load config: %s", err.Error())
-			return
+	if !clusterConfig.HasCompletedStep(types.ClusterCreateStepAuthMap) {
+		if err := ensureEKSAuthMap(ctx, clients, clusterConfig.VPC.RoleArn); err != nil {
+			return errors.Wrap(err, "failed to ensure aws-auth configmap")
 		}
 
-		for _, gridConfig := range c.GridConfigs {
-			if gridConfig.Name == gridName {
-				gridConfig.ClusterConfigs = append(gridConfig.ClusterConfigs, &clusterConfig)
-			}
+		if err := completeCreateStep(store, gridName, clusterConfig, types.ClusterCreateStepAuthMap); err != nil {
+			return err
 		}
-		if err := saveConfig(c, configFilePath); err != nil {
-			completedCh <- fmt.Sprintf("error saving config: %s", err.Error())
+	}
+
+	if !clusterConfig.HasCompletedStep(types.ClusterCreateStepNodes) {
+		nodeGroup, err := describeEKSNodeGroup(ctx, cfg, clusterConfig.Name, clusterConfig.NodeGroupName)
+		if err != nil {
+			return errors.Wrap(err, "failed to get eks cluster node pool")
+		}
+
+		log.Info("Waiting for nodes to become ready")
+		if err := waitForNodes(ctx, clients, nodeGroup); err != nil {
+			return errors.Wrap(err, "failed to wait for nodes to join")
 		}
-	}()
 
-	if err := waitForAPIServer(&clusterConfig); err != nil {
-		completedCh <- fmt.Sprintf("failed to wait for API server: %s", err.Error())
-		return
+		if err := completeCreateStep(store, gridName, clusterConfig, types.ClusterCreateStepNodes); err != nil {
+			return err
+		}
 	}
 
-	if err := ensureEKSAuthMap(&clusterConfig, vpc.RoleArn); err != nil {
-		completedCh <- fmt.Sprintf("failed to ensure aws-auth configmap: %s", err.Error())
-		return
+	if !clusterConfig.HasCompletedStep(types.ClusterCreateStepStorageClass) {
+		if err := ensureEKSDefaultStorageClass(ctx, clients); err != nil {
+			return errors.Wrap(err, "failed to ensure default storage class")
+		}
+
+		if err := completeCreateStep(store, gridName, clusterConfig, types.ClusterCreateStepStorageClass); err != nil {
+			return err
+		}
 	}
 
-	log.Info("Waiting for nodes to become ready")
-	if err := waitForNodes(&clusterConfig, nodeGroup); err != nil {
-		completedCh <- fmt.Sprintf("failed to wait for nodes to join: %s", err.Error())
-		return
+	clusterConfig.Creating = false
+	if err := updateClusterInConfig(store, gridName, clusterConfig); err != nil {
+		return errors.Wrap(err, "failed to mark cluster created")
 	}
 
-	if err := ensureEKSDefaultStorageClass(&clusterConfig); err != nil {
-		completedCh <- fmt.Sprintf("failed to ensure default storage class: %s", err.Error())
-		return
+	return nil
+}
+
+// completeCreateStep records the step, and any resource IDs already set on clusterConfig, in the config
+func completeCreateStep(store ConfigStore, gridName string, clusterConfig *types.ClusterConfig, step types.ClusterCreateStep) error {
+	clusterConfig.CompletedSteps = append(clusterConfig.CompletedSteps, step)
+	if err := updateClusterInConfig(store, gridName, clusterConfig); err != nil {
+		return errors.Wrapf(err, "failed to save %s step", step)
 	}
 
-	completedCh <- ""
+	return nil
 }
 
-func ensureEKSAuthMap(c *types.ClusterConfig, roleArn string) error {
+func ensureEKSAuthMap(ctx context.Context, clients *cluster.Clients, roleArn string) error {
 	// ARN can't be a path, so if it's more than 2 parts, everything in the middle needs to be removed
 	arnParts := strings.Split(roleArn, "/")
 	if len(arnParts) > 2 {
@@ -329,14 +448,14 @@ data:
 
 `
 	yamlDoc = fmt.Sprintf(yamlDoc, roleArn)
-	if err := kubectl.Apply(c, yamlDoc); err != nil {
+	if err := kubectl.Apply(ctx, clients, yamlDoc); err != nil {
 		return errors.Wrap(err, "failed to apply aws-auth configmap")
 	}
 
 	return nil
 }
 
-func ensureEKSDefaultStorageClass(c *types.ClusterConfig) error {
+func ensureEKSDefaultStorageClass(ctx context.Context, clients *cluster.Clients) error {
 	// This is a workaround for apps that specify `default` as their storage class
 
 	yamlDoc := `
@@ -351,30 +470,30 @@ provisioner: kubernetes.io/aws-ebs
 reclaimPolicy: Delete
 volumeBindingMode: WaitForFirstConsumer`
 
-	if err := kubectl.Apply(c, yamlDoc); err != nil {
+	if err := kubectl.Apply(ctx, clients, yamlDoc); err != nil {
 		return errors.Wrap(err, "failed to apply aws-auth configmap")
 	}
 
 	return nil
 }
 
-func waitForNodes(c *types.ClusterConfig, nodeGroup *ekstypes.Nodegroup) error {
+func waitForNodes(ctx context.Context, clients *cluster.Clients, nodeGroup *ekstypes.Nodegroup) error {
 	sleepTime := 10 * time.Second
 	var lastError error
 	for i := 0; i < 24; i++ {
-		nodes, err := kubectl.GetNodes(c)
+		nodes, err := kubectl.GetNodes(ctx, clients)
 		if err != nil {
 			lastError = err
-			time.Sleep(sleepTime)
+			if err := sleep(ctx, sleepTime); err != nil {
+				return err
+			}
 			continue
 		}
 
 		numReady := 0
 		for _, n := range nodes.Items {
-			for _, c := range n.Status.Conditions {
-				if c.Reason == "KubeletReady" && c.Status == "True" && c.Type == "Ready" {
-					numReady++
-				}
+			if kubectl.IsNodeReady(n) {
+				numReady++
 			}
 		}
 
@@ -387,22 +506,26 @@ func waitForNodes(c *types.ClusterConfig, nodeGroup *ekstypes.Nodegroup) error {
 			return nil
 		}
 
-		time.Sleep(sleepTime)
+		if err := sleep(ctx, sleepTime); err != nil {
+			return err
+		}
 	}
 
 	return errors.Errorf("timed out, last error was %v", lastError)
 }
 
-func waitForAPIServer(c *types.ClusterConfig) error {
+func waitForAPIServer(ctx context.Context, clients *cluster.Clients) error {
 	sleepTime := 10 * time.Second
 	var lastError error
 	for i := 0; i < 24; i++ {
-		lastError = kubectl.CheckAPIServer(c)
+		lastError = kubectl.CheckAPIServer(ctx, clients)
 		if lastError == nil {
 			return nil
 		}
 
-		time.Sleep(sleepTime)
+		if err := sleep(ctx, sleepTime); err != nil {
+			return err
+		}
 	}
 
 	return errors.Errorf("timed out, last error was %v", lastError)
@@ -411,3 +534,13 @@ func waitForAPIServer(c *types.ClusterConfig) error {
 func generateClusterName() string {
 	return fmt.Sprintf("grid-%x", md5.Sum([]byte(fmt.Sprintf("%d", time.Now().UnixNano()))))
 }
+
+// sleep waits for d, returning early with the context error if ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,213 @@
+package grid
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateClusterErrors(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	store := NewFileStore(filepath.Join(tmpDir, "config"))
+
+	g := &types.Grid{
+		Name: "test",
+		Spec: types.GridSpec{
+			Clusters: []*types.ClusterSpec{
+				{},
+				{
+					EKS: &types.EKSSpec{},
+				},
+				{
+					EKS: &types.EKSSpec{
+						ExistingCluster: &types.EKSExistingClusterSpec{
+							ClusterName: "existing",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err = Create(context.Background(), store, g, CreateOptions{}, logger.NewTerminalLogger())
+	req.Error(err)
+
+	multiErr, ok := err.(*kerrors.MultiError)
+	req.True(ok)
+
+	// each cluster reports exactly one error, even when it fails early
+	req.Len(multiErr.Errors, 3)
+	assert.EqualError(t, multiErr.Errors[0], "create cluster 0: unknown cluster")
+	assert.EqualError(t, multiErr.Errors[1], "create cluster 1: eks cluster must have new or existing")
+	assert.EqualError(t, multiErr.Errors[2], "create cluster existing: failed to read access key id: unable to find supported value")
+}
+
+func Test_CreateRollsBackUnfinishedClusters(t *testing.T) {
+	defer func(f func(context.Context, string, *types.ClusterSpec, ConfigStore, logger.Logger) error) {
+		createClusterFunc = f
+	}(createClusterFunc)
+	defer func(f func(context.Context, *types.ClusterConfig, logger.Logger) error) {
+		deleteClusterFunc = f
+	}(deleteClusterFunc)
+
+	tests := []struct {
+		name    string
+		cancel  bool
+		keep    bool
+		wantErr string
+	}{
+		{
+			name:    "step failed",
+			wantErr: "create cluster partial: failed to create eks cluster control plane",
+		},
+		{
+			name:    "cancelled",
+			cancel:  true,
+			wantErr: context.Canceled.Error(),
+		},
+		{
+			name:    "kept for resume",
+			cancel:  true,
+			keep:    true,
+			wantErr: context.Canceled.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			store := NewFileStore(filepath.Join(t.TempDir(), "config"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			finished := make(chan struct{})
+			createClusterFunc = func(ctx context.Context, gridName string, cluster *types.ClusterSpec, store ConfigStore, log logger.Logger) error {
+				name := cluster.EKS.ExistingCluster.ClusterName
+				if name == "finished" {
+					defer close(finished)
+					return addClusterToConfig(store, gridName, &types.ClusterConfig{Name: name, Provider: "aws"})
+				}
+
+				// the second cluster gets as far as its vpc once the first has finished
+				<-finished
+				partial := &types.ClusterConfig{Name: name, Provider: "aws", Creating: true}
+				if err := addClusterToConfig(store, gridName, partial); err != nil {
+					return err
+				}
+				partial.VPC = &types.AWSVPC{ID: "vpc-123"}
+				if err := completeCreateStep(store, gridName, partial, types.ClusterCreateStepVPC); err != nil {
+					return err
+				}
+
+				if tt.cancel {
+					cancel()
+					return ctx.Err()
+				}
+				return errors.New("failed to create eks cluster control plane")
+			}
+
+			deleted := []string{}
+			deleteClusterFunc = func(ctx context.Context, c *types.ClusterConfig, log logger.Logger) error {
+				// the rollback still runs after the create's context is cancelled
+				req.NoError(ctx.Err())
+				req.True(c.HasCompletedStep(types.ClusterCreateStepVPC))
+				deleted = append(deleted, c.Name)
+				return nil
+			}
+
+			g := &types.Grid{
+				Name: "test",
+				Spec: types.GridSpec{
+					Clusters: []*types.ClusterSpec{
+						{EKS: &types.EKSSpec{ExistingCluster: &types.EKSExistingClusterSpec{ClusterName: "finished"}}},
+						{EKS: &types.EKSSpec{ExistingCluster: &types.EKSExistingClusterSpec{ClusterName: "partial"}}},
+					},
+				},
+			}
+
+			err := Create(ctx, store, g, CreateOptions{KeepOnFailure: tt.keep}, logger.NewTerminalLogger())
+			req.ErrorContains(err, tt.wantErr)
+
+			gridConfig, err := getGridConfig(store, "test")
+			req.NoError(err)
+			if tt.keep {
+				req.Empty(deleted)
+				req.Len(gridConfig.ClusterConfigs, 2)
+				req.True(gridConfig.ClusterConfigs[1].Creating)
+				req.True(gridConfig.ClusterConfigs[1].HasCompletedStep(types.ClusterCreateStepVPC))
+				return
+			}
+
+			req.Equal([]string{"partial"}, deleted)
+			req.Len(gridConfig.ClusterConfigs, 1)
+			req.Equal("finished", gridConfig.ClusterConfigs[0].Name)
+		})
+	}
+}
+
+func Test_CreateRemovesEmptyGrid(t *testing.T) {
+	req := require.New(t)
+
+	defer func(f func(context.Context, string, *types.ClusterSpec, ConfigStore, logger.Logger) error) {
+		createClusterFunc = f
+	}(createClusterFunc)
+	createClusterFunc = func(ctx context.Context, gridName string, cluster *types.ClusterSpec, store ConfigStore, log logger.Logger) error {
+		return errors.New("failed")
+	}
+
+	store := NewFileStore(filepath.Join(t.TempDir(), "config"))
+	err := Create(context.Background(), store, &types.Grid{Name: "empty", Spec: types.GridSpec{Clusters: []*types.ClusterSpec{{}}}}, CreateOptions{}, logger.NewTerminalLogger())
+	req.ErrorContains(err, "failed")
+
+	_, err = getGridConfig(store, "empty")
+	req.EqualError(err, "grid empty not found")
+}
+
+func Test_completeCreateStep(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	store := NewFileStore(filepath.Join(tmpDir, "config"))
+
+	req.NoError(addGridToConfig(store, "grid"))
+	clusterConfig := &types.ClusterConfig{
+		Name:     "grid-abc",
+		Provider: "aws",
+		Creating: true,
+	}
+	req.NoError(addClusterToConfig(store, "grid", clusterConfig))
+
+	clusterConfig.VPC = &types.AWSVPC{ID: "vpc-123"}
+	req.NoError(completeCreateStep(store, "grid", clusterConfig, types.ClusterCreateStepVPC))
+
+	gridConfig, err := getGridConfig(store, "grid")
+	req.NoError(err)
+	req.Len(gridConfig.ClusterConfigs, 1)
+
+	stored := gridConfig.ClusterConfigs[0]
+	assert.True(t, stored.Creating)
+	assert.True(t, stored.HasCompletedStep(types.ClusterCreateStepVPC))
+	assert.False(t, stored.HasCompletedStep(types.ClusterCreateStepControlPlane))
+	assert.Equal(t, "vpc-123", stored.VPC.ID)
+
+	// a cluster that was removed from the config can't be checkpointed
+	req.NoError(removeClusterFromConfig("grid", "grid-abc", store))
+	err = completeCreateStep(store, "grid", clusterConfig, types.ClusterCreateStepControlPlane)
+	assert.EqualError(t, err, "failed to save controlPlane step: cluster grid-abc not found in grid grid")
+}
@@ -2,103 +2,146 @@ package grid
 
 import (
 	"context"
-	"fmt"
-	"sync"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/pkg/errors"
+	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
 )
 
-func Delete(configFilePath string, g *types.Grid, log logger.Logger) error {
-	gridConfigs, err := List(configFilePath)
+// deleteConcurrency is the max number of clusters deleted at the same time
+const deleteConcurrency = 8
+
+// Delete tears down the clusters in a grid using what was stored in the config when they were created.
+// Existing clusters are only removed from the config, they are not deleted.
+// If clusterName is empty, every cluster is deleted and the grid is removed from the config.
+func Delete(ctx context.Context, store ConfigStore, gridName string, clusterName string, log logger.Logger) error {
+	gridConfig, err := getGridConfig(store, gridName)
 	if err != nil {
-		return errors.Wrap(err, "failed to list grid configs")
+		return err
 	}
 
-	wg := sync.WaitGroup{}
-	for _, gridConfig := range gridConfigs {
-		for _, clusterConfig := range gridConfig.ClusterConfigs {
-			for _, cluster := range g.Spec.Clusters {
-				if cluster.EKS == nil && cluster.EKS.NewCluster == nil {
-					continue
-				}
+	clusterConfigs := []*types.ClusterConfig{}
+	for _, c := range gridConfig.ClusterConfigs {
+		if clusterName == "" || c.Name == clusterName {
+			clusterConfigs = append(clusterConfigs, c)
+		}
+	}
+	if clusterName != "" && len(clusterConfigs) == 0 {
+		return errors.Errorf("cluster %s not found in grid %s", clusterName, gridName)
+	}
 
-				if cluster.EKS.NewCluster.Name == "" {
-					return errors.New("cluster has no name")
-				}
-				if clusterConfig.Name != cluster.EKS.NewCluster.Name {
-					continue
+	tasks := []parallel.Task[struct{}]{}
+	for _, c := range clusterConfigs {
+		c := c
+		tasks = append(tasks, func(ctx context.Context) (struct{}, error) {
+			clusterLog := log.ForCluster(c.Name)
+			if err := deleteClusterFunc(ctx, c, clusterLog); err != nil {
+				clusterLog.Error(err)
+				return struct{}{}, err
+			}
+			if err := removeClusterFromConfig(gridName, c.Name, store); err != nil {
+				clusterLog.Error(err)
+				return struct{}{}, err
+			}
+
+			// the cluster is gone, so a stale merged context isn't worth failing the delete over
+			for _, path := range mergedKubeconfigPaths(c) {
+				if err := cluster.RemoveFromKubeconfig(path, cluster.ContextName(gridName, c.Name)); err != nil {
+					clusterLog.Info("Failed to remove cluster %s from %s: %v", c.Name, path, err)
 				}
+			}
 
-				wg.Add(1)
-				go func(config *types.ClusterConfig, cluster *types.ClusterSpec) {
-					defer wg.Done()
+			return struct{}{}, nil
+		})
+	}
 
-					err := deleteCluster(config, cluster, log)
-					if err != nil {
-						fmt.Printf("cluster %s delete failed with error: %v\n", config.Name, err)
-					}
-				}(clusterConfig, cluster)
-			}
+	deleteErrors := []error{}
+	for _, result := range parallel.Run(ctx, deleteConcurrency, tasks) {
+		if result.Err != nil {
+			deleteErrors = append(deleteErrors, errors.Wrapf(result.Err, "delete cluster %s", clusterConfigs[result.Index].Name))
 		}
 	}
+	if len(deleteErrors) > 0 {
+		return &kerrors.MultiError{Errors: deleteErrors}
+	}
 
-	wg.Wait()
-
-	if err := removeGridFromConfig(g.Name, configFilePath); err != nil {
-		return errors.Wrap(err, "failed to remove grid from config")
+	if clusterName == "" {
+		if err := removeGridFromConfig(gridName, store); err != nil {
+			return errors.Wrap(err, "failed to remove grid from config")
+		}
 	}
 
 	return nil
 }
 
-func deleteCluster(c *types.ClusterConfig, cluster *types.ClusterSpec, log logger.Logger) error {
+// deleteClusterFunc deletes a cluster's resources, tests replace it
+var deleteClusterFunc = deleteCluster
+
+func deleteCluster(ctx context.Context, c *types.ClusterConfig, log logger.Logger) error {
+	if c.IsExisting {
+		log.Info("Removing existing cluster %s from grid, the cluster will not be deleted", c.Name)
+		return nil
+	}
+
 	if c.Provider == "aws" {
-		return deleteNewEKSCluster(c, cluster.EKS, log)
+		return deleteNewEKSCluster(ctx, c, log)
 	}
 
-	return nil
+	return errors.Errorf("unknown provider %q", c.Provider)
 }
 
-func deleteNewEKSCluster(c *types.ClusterConfig, cluster *types.EKSSpec, log logger.Logger) error {
-	if cluster.NewCluster == nil {
-		return errors.New("cluster spec is nil")
+func deleteNewEKSCluster(ctx context.Context, c *types.ClusterConfig, log logger.Logger) error {
+	if c.AccessKeyID == nil || c.SecretAccessKey == nil {
+		return errors.New("cluster config has no aws credentials, it may have been created by an older version of kgrid")
 	}
 
-	log.Info("Deleting EKS cluster %s", cluster.NewCluster.Name)
+	log.Info("Deleting EKS cluster %s", c.Name)
 
-	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(c.Region))
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(c.Region))
 	if err != nil {
 		return errors.Wrap(err, "failed to load aws config")
 	}
 
-	accessKeyID, err := cluster.NewCluster.AccessKeyID.String()
+	accessKeyID, err := c.AccessKeyID.String()
 	if err != nil {
 		return errors.Wrap(err, "failed to get access key id")
 	}
-	secretAccessKey, err := cluster.NewCluster.SecretAccessKey.String()
+	secretAccessKey, err := c.SecretAccessKey.String()
 	if err != nil {
 		return errors.Wrap(err, "failed to get secret access key")
 	}
 
 	cfg.Credentials = credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
 
+	nodeGroupName := c.NodeGroupName
+	if nodeGroupName == "" {
+		nodeGroupName = c.Name
+	}
+
+	return deleteEKSClusterResources(ctx, cfg, c.Name, nodeGroupName, log)
+}
+
+// deleteEKSClusterResources deletes the node group and control plane of a cluster created by kgrid
+func deleteEKSClusterResources(ctx context.Context, cfg aws.Config, clusterName string, nodeGroupName string, log logger.Logger) error {
 	log.Info("Deleting node group for EKS cluster (this may take a few minutes)")
-	err = deleteEKSNodeGroup(cfg, cluster.NewCluster.Name, cluster.NewCluster.Name)
+	err := deleteEKSNodeGroup(ctx, cfg, clusterName, nodeGroupName)
 	if err != nil {
 		return errors.Wrap(err, "failed to delete node group")
 	}
 
-	err = waitEKSNodeGroupGone(cfg, cluster.NewCluster.Name, cluster.NewCluster.Name)
+	err = waitEKSNodeGroupGone(ctx, cfg, clusterName, nodeGroupName)
 	if err != nil {
 		return errors.Wrap(err, "failed to wait for node group delete")
 	}
 
 	log.Info("Deleting EKS cluster")
-	err = deleteEKSCluster(cfg, cluster.NewCluster.Name)
+	err = deleteEKSCluster(ctx, cfg, clusterName)
 	if err != nil {
 		return errors.Wrap(err, "failed to delete cluster")
 	}
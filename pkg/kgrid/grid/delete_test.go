@@ -0,0 +1,160 @@
+package grid
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func Test_Delete(t *testing.T) {
+	gridsConfig := &types.GridsConfig{
+		GridConfigs: []*types.GridConfig{
+			{
+				Name: "grid",
+				ClusterConfigs: []*types.ClusterConfig{
+					{Name: "shared-1", Provider: "aws", IsExisting: true},
+					{Name: "shared-2", Provider: "aws", IsExisting: true},
+					{Name: "no-credentials", Provider: "aws"},
+				},
+			},
+			{
+				Name: "other",
+				ClusterConfigs: []*types.ClusterConfig{
+					{Name: "shared-1", Provider: "aws", IsExisting: true},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		gridName      string
+		clusterName   string
+		expectErr     string
+		expectedGrids map[string][]string
+	}{
+		{
+			name:      "unknown grid",
+			gridName:  "missing",
+			expectErr: "grid missing not found",
+		},
+		{
+			name:        "unknown cluster",
+			gridName:    "grid",
+			clusterName: "missing",
+			expectErr:   "cluster missing not found in grid grid",
+		},
+		{
+			name:        "single existing cluster",
+			gridName:    "grid",
+			clusterName: "shared-1",
+			expectedGrids: map[string][]string{
+				"grid":  {"shared-2", "no-credentials"},
+				"other": {"shared-1"},
+			},
+		},
+		{
+			name:      "failed cluster keeps the grid",
+			gridName:  "grid",
+			expectErr: `["delete cluster no-credentials: cluster config has no aws credentials, it may have been created by an older version of kgrid"]`,
+			expectedGrids: map[string][]string{
+				"grid":  {"no-credentials"},
+				"other": {"shared-1"},
+			},
+		},
+		{
+			name:     "whole grid",
+			gridName: "other",
+			expectedGrids: map[string][]string{
+				"grid": {"shared-1", "shared-2", "no-credentials"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			tmpDir, err := ioutil.TempDir("", "")
+			req.NoError(err)
+			defer os.RemoveAll(tmpDir)
+			store := NewFileStore(filepath.Join(tmpDir, "config"))
+			req.NoError(store.Save(gridsConfig))
+
+			defer func(path string) { cluster.MergedKubeconfigPath = path }(cluster.MergedKubeconfigPath)
+			cluster.MergedKubeconfigPath = filepath.Join(tmpDir, "kubeconfig")
+
+			err = Delete(context.Background(), store, test.gridName, test.clusterName, logger.NewTerminalLogger())
+			if test.expectErr != "" {
+				req.EqualError(err, test.expectErr)
+				if _, ok := err.(*kerrors.MultiError); !ok {
+					return
+				}
+			} else {
+				req.NoError(err)
+			}
+
+			cfg, err := store.Load()
+			req.NoError(err)
+
+			grids := map[string][]string{}
+			for _, g := range cfg.GridConfigs {
+				grids[g.Name] = []string{}
+				for _, c := range g.ClusterConfigs {
+					grids[g.Name] = append(grids[g.Name], c.Name)
+				}
+			}
+			assert.Equal(t, test.expectedGrids, grids)
+		})
+	}
+}
+
+func Test_DeleteRemovesMergedContexts(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir := t.TempDir()
+	store := NewFileStore(filepath.Join(tmpDir, "config"))
+	req.NoError(store.Save(&types.GridsConfig{
+		GridConfigs: []*types.GridConfig{
+			{
+				Name:           "grid",
+				ClusterConfigs: []*types.ClusterConfig{{Name: "shared", Provider: "aws", IsExisting: true}},
+			},
+		},
+	}))
+
+	defer func(path string) { cluster.MergedKubeconfigPath = path }(cluster.MergedKubeconfigPath)
+	cluster.MergedKubeconfigPath = filepath.Join(tmpDir, "default-kubeconfig")
+
+	// merged into a file other than the default with kubeconfig --merge --file
+	kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+	name := cluster.ContextName("grid", "shared")
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[name] = &clientcmdapi.Cluster{Server: "https://shared"}
+	kubeconfig.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: "abc"}
+	kubeconfig.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	req.NoError(clientcmd.WriteToFile(*kubeconfig, kubeconfigPath))
+	req.NoError(RecordMergedKubeconfig(store, "grid", kubeconfigPath, "shared"))
+
+	gridConfig, err := getGridConfig(store, "grid")
+	req.NoError(err)
+	req.Equal([]string{kubeconfigPath}, gridConfig.ClusterConfigs[0].MergedKubeconfigs)
+
+	req.NoError(Delete(context.Background(), store, "grid", "", logger.NewTerminalLogger()))
+
+	remaining, err := clientcmd.LoadFromFile(kubeconfigPath)
+	req.NoError(err)
+	req.Empty(remaining.Contexts)
+	req.Empty(remaining.AuthInfos)
+}
@@ -39,15 +39,15 @@ func isEKSNotFound(err error) bool {
 	return ok
 }
 
-func GetEKSClusterKubeConfig(region string, accessKeyID string, secretAccessKey string, clusterName string) (string, error) {
-	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+func GetEKSClusterKubeConfig(ctx context.Context, region string, accessKeyID string, secretAccessKey string, clusterName string) (string, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to load aws config")
 	}
 	cfg.Credentials = credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
 
 	svc := eks.NewFromConfig(cfg)
-	result, err := svc.DescribeCluster(context.Background(), &eks.DescribeClusterInput{
+	result, err := svc.DescribeCluster(ctx, &eks.DescribeClusterInput{
 		Name: aws.String(clusterName),
 	})
 	if err != nil {
@@ -72,32 +72,34 @@ users:
 - name: aws
   user:
     exec:
-        apiVersion: client.authentication.k8s.io/v1alpha1
+        apiVersion: client.authentication.k8s.io/v1beta1
         command: aws
         args:
         - "eks"
         - "get-token"
         - "--cluster-name"
         - "%s"
+        - "--region"
+        - "%s"
         env:
         - name: AWS_ACCESS_KEY_ID
           value: %s
         - name: AWS_SECRET_ACCESS_KEY
           value: %s
-`, *result.Cluster.Endpoint, *result.Cluster.CertificateAuthority.Data, clusterName, accessKeyID, secretAccessKey)
+`, *result.Cluster.Endpoint, *result.Cluster.CertificateAuthority.Data, clusterName, region, accessKeyID, secretAccessKey)
 
 	return b, nil
 }
 
-func GetEKSClusterNodePoolIsReady(region string, accessKeyID string, secretAccessKey string, clusterName string) (bool, error) {
-	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+func GetEKSClusterNodePoolIsReady(ctx context.Context, region string, accessKeyID string, secretAccessKey string, clusterName string) (bool, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
 	if err != nil {
 		return false, errors.Wrap(err, "failed to load aws config")
 	}
 	cfg.Credentials = credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
 
 	svc := eks.NewFromConfig(cfg)
-	result, err := svc.DescribeNodegroup(context.Background(), &eks.DescribeNodegroupInput{
+	result, err := svc.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
 		ClusterName:   aws.String(clusterName),
 		NodegroupName: aws.String(clusterName),
 	})
@@ -110,15 +112,15 @@ func GetEKSClusterNodePoolIsReady(region string, accessKeyID string, secretAcces
 
 // getEKSClusterIsReady will return a bool if the cluster is completely ready for workloads
 // we look at the cluster status in the AWS response to be "active"
-func getEKSClusterIsReady(region string, accessKeyID string, secretAccessKey string, clusterName string) (bool, error) {
-	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+func getEKSClusterIsReady(ctx context.Context, region string, accessKeyID string, secretAccessKey string, clusterName string) (bool, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
 	if err != nil {
 		return false, errors.Wrap(err, "failed to load aws config")
 	}
 	cfg.Credentials = credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
 
 	svc := eks.NewFromConfig(cfg)
-	result, err := svc.DescribeCluster(context.Background(), &eks.DescribeClusterInput{
+	result, err := svc.DescribeCluster(ctx, &eks.DescribeClusterInput{
 		Name: aws.String(clusterName),
 	})
 	if err != nil {
@@ -129,7 +131,7 @@ func getEKSClusterIsReady(region string, accessKeyID string, secretAccessKey str
 }
 
 // ensureEKSCluster will create the deterministic vpc for our clusters
-func ensureEKSClusterVPC(cfg aws.Config) (*types.AWSVPC, error) {
+func ensureEKSClusterVPC(ctx context.Context, cfg aws.Config) (*types.AWSVPC, error) {
 	vpc := types.AWSVPC{}
 
 	// all clusters end ip in a single VPC with a tag "replicatedhq-kubectl-grid=1"
@@ -146,7 +148,7 @@ func ensureEKSClusterVPC(cfg aws.Config) (*types.AWSVPC, error) {
 			},
 		},
 	}
-	describeVPCsResult, err := svc.DescribeVpcs(context.Background(), describeVPCsInput)
+	describeVPCsResult, err := svc.DescribeVpcs(ctx, describeVPCsInput)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to describe VPCs")
 	}
@@ -168,7 +170,7 @@ func ensureEKSClusterVPC(cfg aws.Config) (*types.AWSVPC, error) {
 				},
 			},
 		}
-		createVPCResult, err := svc.CreateVpc(context.Background(), createVPCInput)
+		createVPCResult, err := svc.CreateVpc(ctx, createVPCInput)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create VPC")
 		}
@@ -176,13 +178,13 @@ func ensureEKSClusterVPC(cfg aws.Config) (*types.AWSVPC, error) {
 		vpc.ID = *createVPCResult.Vpc.VpcId
 	}
 
-	igwID, err := ensureInternetGateway(cfg, vpc.ID)
+	igwID, err := ensureInternetGateway(ctx, cfg, vpc.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure internet gateway")
 	}
 	vpc.InternetGatewayID = igwID
 
-	securityGroupID, err := ensureEKSClusterSecurityGroup(cfg, vpc.ID)
+	securityGroupID, err := ensureEKSClusterSecurityGroup(ctx, cfg, vpc.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure security group")
 	}
@@ -190,43 +192,43 @@ func ensureEKSClusterVPC(cfg aws.Config) (*types.AWSVPC, error) {
 		securityGroupID,
 	}
 
-	privateSubnetIDs, err := ensurePrivateEKSSubnets(cfg, vpc.ID)
+	privateSubnetIDs, err := ensurePrivateEKSSubnets(ctx, cfg, vpc.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure private subnets")
 	}
 	vpc.PrivateSubnetIDs = privateSubnetIDs
 
-	publicSubnetID, err := ensurePublicEKSSubnet(cfg, vpc.ID)
+	publicSubnetID, err := ensurePublicEKSSubnet(ctx, cfg, vpc.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure public subnets")
 	}
 	vpc.PublicSubnetID = publicSubnetID
 
-	err = ensurePublicSubnetRouteTable(cfg, vpc.ID, vpc.PublicSubnetID, vpc.InternetGatewayID)
+	err = ensurePublicSubnetRouteTable(ctx, cfg, vpc.ID, vpc.PublicSubnetID, vpc.InternetGatewayID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure public subnet route table")
 	}
 
-	eipAllocationID, err := ensureElasticIP(cfg)
+	eipAllocationID, err := ensureElasticIP(ctx, cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure elastic ip")
 	}
 	vpc.EIPAllocationID = eipAllocationID
 
-	natGatewayID, err := ensureNATGateway(cfg, vpc.PublicSubnetID, vpc.EIPAllocationID)
+	natGatewayID, err := ensureNATGateway(ctx, cfg, vpc.PublicSubnetID, vpc.EIPAllocationID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure nat gateway")
 	}
 	vpc.NATGatewayID = natGatewayID
 
 	for _, subnetID := range vpc.PrivateSubnetIDs {
-		err = ensurePrivateSubnetRouteTable(cfg, vpc.ID, subnetID, vpc.NATGatewayID)
+		err = ensurePrivateSubnetRouteTable(ctx, cfg, vpc.ID, subnetID, vpc.NATGatewayID)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to ensure private subnet route table")
 		}
 	}
 
-	roleArn, err := ensureEKSRoleARN(cfg)
+	roleArn, err := ensureEKSRoleARN(ctx, cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure role arn")
 	}
@@ -235,8 +237,7 @@ func ensureEKSClusterVPC(cfg aws.Config) (*types.AWSVPC, error) {
 	return &vpc, nil
 }
 
-func ensureInternetGateway(cfg aws.Config, vpcID string) (string, error) {
-	ctx := context.Background()
+func ensureInternetGateway(ctx context.Context, cfg aws.Config, vpcID string) (string, error) {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeInternetGatewaysInput := &ec2.DescribeInternetGatewaysInput{
@@ -288,7 +289,7 @@ func ensureInternetGateway(cfg aws.Config, vpcID string) (string, error) {
 	return *createInternetGatewayResult.InternetGateway.InternetGatewayId, nil
 }
 
-func ensureEKSClusterSecurityGroup(cfg aws.Config, vpcID string) (string, error) {
+func ensureEKSClusterSecurityGroup(ctx context.Context, cfg aws.Config, vpcID string) (string, error) {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeSecurityGroupsInput := &ec2.DescribeSecurityGroupsInput{
@@ -301,7 +302,7 @@ func ensureEKSClusterSecurityGroup(cfg aws.Config, vpcID string) (string, error)
 			},
 		},
 	}
-	describeSecurityGroupsResult, err := svc.DescribeSecurityGroups(context.Background(), describeSecurityGroupsInput)
+	describeSecurityGroupsResult, err := svc.DescribeSecurityGroups(ctx, describeSecurityGroupsInput)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to describe security groups")
 	}
@@ -325,7 +326,7 @@ func ensureEKSClusterSecurityGroup(cfg aws.Config, vpcID string) (string, error)
 			},
 		},
 	}
-	createSecurityGroupResult, err := svc.CreateSecurityGroup(context.Background(), createSecurityGroupInput)
+	createSecurityGroupResult, err := svc.CreateSecurityGroup(ctx, createSecurityGroupInput)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create security group")
 	}
@@ -333,7 +334,7 @@ func ensureEKSClusterSecurityGroup(cfg aws.Config, vpcID string) (string, error)
 	return *createSecurityGroupResult.GroupId, nil
 }
 
-func ensurePrivateEKSSubnets(cfg aws.Config, vpcID string) ([]string, error) {
+func ensurePrivateEKSSubnets(ctx context.Context, cfg aws.Config, vpcID string) ([]string, error) {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeSubnetsInput := &ec2.DescribeSubnetsInput{
@@ -347,7 +348,7 @@ func ensurePrivateEKSSubnets(cfg aws.Config, vpcID string) ([]string, error) {
 			},
 		},
 	}
-	describeSubnetsResult, err := svc.DescribeSubnets(context.Background(), describeSubnetsInput)
+	describeSubnetsResult, err := svc.DescribeSubnets(ctx, describeSubnetsInput)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to describe subnets")
 	}
@@ -366,13 +367,13 @@ func ensurePrivateEKSSubnets(cfg aws.Config, vpcID string) ([]string, error) {
 		return subnetIDs, nil
 	}
 
-	subnetID, err := createSubnetInVPC(cfg, vpcID, "172.24.100.0/24", cfg.Region+"a", "replicatedhq/private")
+	subnetID, err := createSubnetInVPC(ctx, cfg, vpcID, "172.24.100.0/24", cfg.Region+"a", "replicatedhq/private")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create private subnet a")
 	}
 	subnetIDs = append(subnetIDs, subnetID)
 
-	subnetID, err = createSubnetInVPC(cfg, vpcID, "172.24.101.0/24", cfg.Region+"b", "replicatedhq/private")
+	subnetID, err = createSubnetInVPC(ctx, cfg, vpcID, "172.24.101.0/24", cfg.Region+"b", "replicatedhq/private")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create private subnet b")
 	}
@@ -381,8 +382,7 @@ func ensurePrivateEKSSubnets(cfg aws.Config, vpcID string) ([]string, error) {
 	return subnetIDs, nil
 }
 
-func ensurePublicEKSSubnet(cfg aws.Config, vpcID string) (string, error) {
-	ctx := context.Background()
+func ensurePublicEKSSubnet(ctx context.Context, cfg aws.Config, vpcID string) (string, error) {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeSubnetsInput := &ec2.DescribeSubnetsInput{
@@ -407,7 +407,7 @@ func ensurePublicEKSSubnet(cfg aws.Config, vpcID string) (string, error) {
 		}
 	}
 
-	subnetID, err := createSubnetInVPC(cfg, vpcID, "172.24.102.0/24", cfg.Region+"a", "replicatedhq/public")
+	subnetID, err := createSubnetInVPC(ctx, cfg, vpcID, "172.24.102.0/24", cfg.Region+"a", "replicatedhq/public")
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create public subnet a")
 	}
@@ -415,8 +415,7 @@ func ensurePublicEKSSubnet(cfg aws.Config, vpcID string) (string, error) {
 	return subnetID, nil
 }
 
-func ensurePrivateSubnetRouteTable(cfg aws.Config, vpcID string, subnetID string, natGatewayID string) error {
-	ctx := context.Background()
+func ensurePrivateSubnetRouteTable(ctx context.Context, cfg aws.Config, vpcID string, subnetID string, natGatewayID string) error {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeRouteTablesInput := &ec2.DescribeRouteTablesInput{
@@ -500,8 +499,7 @@ FindRouteTable:
 	return nil
 }
 
-func ensurePublicSubnetRouteTable(cfg aws.Config, vpcID string, subnetID string, igwID string) error {
-	ctx := context.Background()
+func ensurePublicSubnetRouteTable(ctx context.Context, cfg aws.Config, vpcID string, subnetID string, igwID string) error {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeRouteTablesInput := &ec2.DescribeRouteTablesInput{
@@ -582,8 +580,7 @@ FindRouteTable:
 	return nil
 }
 
-func ensureElasticIP(cfg aws.Config) (string, error) {
-	ctx := context.Background()
+func ensureElasticIP(ctx context.Context, cfg aws.Config) (string, error) {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeAddressesInput := &ec2.DescribeAddressesInput{
@@ -627,8 +624,7 @@ func ensureElasticIP(cfg aws.Config) (string, error) {
 	return *allocateAddressResult.AllocationId, nil
 }
 
-func ensureNATGateway(cfg aws.Config, subnetID string, allocationID string) (string, error) {
-	ctx := context.Background()
+func ensureNATGateway(ctx context.Context, cfg aws.Config, subnetID string, allocationID string) (string, error) {
 	svc := ec2.NewFromConfig(cfg)
 
 	describeNatGatewaysInput := &ec2.DescribeNatGatewaysInput{
@@ -674,15 +670,14 @@ func ensureNATGateway(cfg aws.Config, subnetID string, allocationID string) (str
 
 	gwID := *createNatGatewayResult.NatGateway.NatGatewayId
 
-	if err := waitForNATGateway(cfg, gwID); err != nil {
+	if err := waitForNATGateway(ctx, cfg, gwID); err != nil {
 		return "", errors.Wrap(err, "failed to wait for nat gateway")
 	}
 
 	return gwID, nil
 }
 
-func waitForNATGateway(cfg aws.Config, natGatewayID string) error {
-	ctx := context.Background()
+func waitForNATGateway(ctx context.Context, cfg aws.Config, natGatewayID string) error {
 	svc := ec2.NewFromConfig(cfg)
 
 	for i := 0; i < 10; i++ {
@@ -699,13 +694,15 @@ func waitForNATGateway(cfg aws.Config, natGatewayID string) error {
 			}
 		}
 
-		time.Sleep(10 * time.Second)
+		if err := sleep(ctx, 10*time.Second); err != nil {
+			return err
+		}
 	}
 
 	return errors.New("timed out")
 }
 
-func createSubnetInVPC(cfg aws.Config, vpcID string, cidrBlock string, az string, tag string) (string, error) {
+func createSubnetInVPC(ctx context.Context, cfg aws.Config, vpcID string, cidrBlock string, az string, tag string) (string, error) {
 	svc := ec2.NewFromConfig(cfg)
 
 	createSubnetInput := &ec2.CreateSubnetInput{
@@ -728,7 +725,7 @@ func createSubnetInVPC(cfg aws.Config, vpcID string, cidrBlock string, az string
 			},
 		},
 	}
-	createSubnetResult, err := svc.CreateSubnet(context.Background(), createSubnetInput)
+	createSubnetResult, err := svc.CreateSubnet(ctx, createSubnetInput)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create subnet")
 	}
@@ -736,14 +733,14 @@ func createSubnetInVPC(cfg aws.Config, vpcID string, cidrBlock string, az string
 	return *createSubnetResult.Subnet.SubnetId, nil
 }
 
-func ensureEKSRoleARN(cfg aws.Config) (string, error) {
+func ensureEKSRoleARN(ctx context.Context, cfg aws.Config) (string, error) {
 	svc := iam.NewFromConfig(cfg)
 
 	listRolesInput := &iam.ListRolesInput{
 		PathPrefix: aws.String("/replicatedhq/"),
 	}
 
-	listRolesResult, err := svc.ListRoles(context.Background(), listRolesInput)
+	listRolesResult, err := svc.ListRoles(ctx, listRolesInput)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to list roles")
 	}
@@ -781,34 +778,34 @@ func ensureEKSRoleARN(cfg aws.Config) (string, error) {
 		Path:                     aws.String("/replicatedhq/"),
 		AssumeRolePolicyDocument: aws.String(string(rolePolicy)),
 	}
-	result, err := svc.CreateRole(context.Background(), &createRoleInput)
+	result, err := svc.CreateRole(ctx, &createRoleInput)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create role")
 	}
 
-	if err := attachRolePolicy(cfg, "arn:aws:iam::aws:policy/AmazonEKSClusterPolicy"); err != nil {
+	if err := attachRolePolicy(ctx, cfg, "arn:aws:iam::aws:policy/AmazonEKSClusterPolicy"); err != nil {
 		return "", errors.Wrap(err, "failed to attach policy 1")
 	}
-	if err := attachRolePolicy(cfg, "arn:aws:iam::aws:policy/AmazonEKSServicePolicy"); err != nil {
+	if err := attachRolePolicy(ctx, cfg, "arn:aws:iam::aws:policy/AmazonEKSServicePolicy"); err != nil {
 		return "", errors.Wrap(err, "failed to attach policy 2")
 	}
-	if err := attachRolePolicy(cfg, "arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy"); err != nil {
+	if err := attachRolePolicy(ctx, cfg, "arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy"); err != nil {
 		return "", errors.Wrap(err, "failed to attach policy 3")
 	}
-	if err := attachRolePolicy(cfg, "arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryReadOnly"); err != nil {
+	if err := attachRolePolicy(ctx, cfg, "arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryReadOnly"); err != nil {
 		return "", errors.Wrap(err, "failed to attach policy 4")
 	}
-	if err := attachRolePolicy(cfg, "arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy"); err != nil {
+	if err := attachRolePolicy(ctx, cfg, "arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy"); err != nil {
 		return "", errors.Wrap(err, "failed to attach policy 5")
 	}
 
 	return *result.Role.Arn, nil
 }
 
-func attachRolePolicy(cfg aws.Config, policyName string) error {
+func attachRolePolicy(ctx context.Context, cfg aws.Config, policyName string) error {
 	svc := iam.NewFromConfig(cfg)
 
-	_, err := svc.AttachRolePolicy(context.Background(), &iam.AttachRolePolicyInput{
+	_, err := svc.AttachRolePolicy(ctx, &iam.AttachRolePolicyInput{
 		PolicyArn: aws.String(policyName),
 		RoleName:  aws.String("kubectl-grid"),
 	})
@@ -823,7 +820,7 @@ func attachRolePolicy(cfg aws.Config, policyName string) error {
 	return nil
 }
 
-func ensureEKSCluterControlPlane(cfg aws.Config, newEKSCluster *types.EKSNewClusterSpec, clusterName string, vpc *types.AWSVPC) (*ekstypes.Cluster, error) {
+func ensureEKSCluterControlPlane(ctx context.Context, cfg aws.Config, newEKSCluster *types.EKSNewClusterSpec, clusterName string, vpc *types.AWSVPC) (*ekstypes.Cluster, error) {
 	svc := eks.NewFromConfig(cfg)
 
 	version := newEKSCluster.Version
@@ -842,7 +839,7 @@ func ensureEKSCluterControlPlane(cfg aws.Config, newEKSCluster *types.EKSNewClus
 		Version: aws.String(version),
 	}
 
-	createdCluster, err := svc.CreateCluster(context.Background(), input)
+	createdCluster, err := svc.CreateCluster(ctx, input)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create eks custer")
 	}
@@ -850,46 +847,39 @@ func ensureEKSCluterControlPlane(cfg aws.Config, newEKSCluster *types.EKSNewClus
 	return createdCluster.Cluster, nil
 }
 
-func waitForClusterToBeActive(newEKSCluster *types.EKSNewClusterSpec, accessKeyID string, secretAccessKey string, clusterName string) error {
-	resultCh := make(chan string)
-	keepTrying := true
-	go func() {
-		for keepTrying {
-			isReady, err := getEKSClusterIsReady(newEKSCluster.Region, accessKeyID, secretAccessKey, clusterName)
-			if err != nil {
-				resultCh <- fmt.Sprintf("error checking cluster status: %s", err.Error())
-				return
-			}
+func waitForClusterToBeActive(ctx context.Context, newEKSCluster *types.EKSNewClusterSpec, accessKeyID string, secretAccessKey string, clusterName string) error {
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Minute)
+	defer cancel()
 
-			if isReady {
-				keepTrying = false
-				resultCh <- ""
-				return
+	for {
+		isReady, err := getEKSClusterIsReady(waitCtx, newEKSCluster.Region, accessKeyID, secretAccessKey, clusterName)
+		if err != nil {
+			if ctx.Err() == nil && waitCtx.Err() != nil {
+				return errors.New("timeout waiting for cluster")
 			}
+			return errors.Wrap(err, "error checking cluster status")
+		}
 
-			time.Sleep(time.Second * 9)
+		if isReady {
+			return nil
 		}
-	}()
 
-	select {
-	case res := <-resultCh:
-		if res != "" {
-			return errors.New(res)
+		if err := sleep(waitCtx, 9*time.Second); err != nil {
+			if ctx.Err() == nil {
+				return errors.New("timeout waiting for cluster")
+			}
+			return err
 		}
-		return nil
-	case <-time.After(20 * time.Minute):
-		keepTrying = false
-		return errors.New("timeout waiting for cluster")
 	}
 }
 
-func ensureEKSClusterNodeGroup(cfg aws.Config, cluster *ekstypes.Cluster, clusterName string, vpc *types.AWSVPC) (*ekstypes.Nodegroup, error) {
+func ensureEKSClusterNodeGroup(ctx context.Context, cfg aws.Config, clusterName string, groupName string, vpc *types.AWSVPC) (*ekstypes.Nodegroup, error) {
 	svc := eks.NewFromConfig(cfg)
 
-	nodeGroup, err := svc.CreateNodegroup(context.Background(), &eks.CreateNodegroupInput{
+	nodeGroup, err := svc.CreateNodegroup(ctx, &eks.CreateNodegroupInput{
 		ClusterName:   aws.String(clusterName),
 		NodeRole:      aws.String(vpc.RoleArn),
-		NodegroupName: aws.String(clusterName),
+		NodegroupName: aws.String(groupName),
 		Subnets:       vpc.PrivateSubnetIDs,
 	})
 	if err != nil {
@@ -899,14 +889,41 @@ func ensureEKSClusterNodeGroup(cfg aws.Config, cluster *ekstypes.Cluster, cluste
 	return nodeGroup.Nodegroup, nil
 }
 
-func deleteEKSNodeGroup(cfg aws.Config, clusterName string, groupName string) error {
+func describeEKSCluster(ctx context.Context, cfg aws.Config, clusterName string) (*ekstypes.Cluster, error) {
+	svc := eks.NewFromConfig(cfg)
+
+	result, err := svc.DescribeCluster(ctx, &eks.DescribeClusterInput{
+		Name: aws.String(clusterName),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe cluster")
+	}
+
+	return result.Cluster, nil
+}
+
+func describeEKSNodeGroup(ctx context.Context, cfg aws.Config, clusterName string, groupName string) (*ekstypes.Nodegroup, error) {
+	svc := eks.NewFromConfig(cfg)
+
+	result, err := svc.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: aws.String(groupName),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe node group")
+	}
+
+	return result.Nodegroup, nil
+}
+
+func deleteEKSNodeGroup(ctx context.Context, cfg aws.Config, clusterName string, groupName string) error {
 	svc := eks.NewFromConfig(cfg)
 
 	deleteNodegroupInput := &eks.DeleteNodegroupInput{
 		ClusterName:   aws.String(clusterName),
 		NodegroupName: aws.String(groupName),
 	}
-	_, err := svc.DeleteNodegroup(context.Background(), deleteNodegroupInput)
+	_, err := svc.DeleteNodegroup(ctx, deleteNodegroupInput)
 	if err != nil && !isEKSNotFound(err) {
 		return errors.Wrap(err, "failed to delete node group")
 	}
@@ -914,7 +931,7 @@ func deleteEKSNodeGroup(cfg aws.Config, clusterName string, groupName string) er
 	return nil
 }
 
-func waitEKSNodeGroupGone(cfg aws.Config, clusterName string, groupName string) error {
+func waitEKSNodeGroupGone(ctx context.Context, cfg aws.Config, clusterName string, groupName string) error {
 	svc := eks.NewFromConfig(cfg)
 
 	for i := 0; i < 24; i++ {
@@ -922,7 +939,7 @@ func waitEKSNodeGroupGone(cfg aws.Config, clusterName string, groupName string)
 			ClusterName:   aws.String(clusterName),
 			NodegroupName: aws.String(groupName),
 		}
-		_, err := svc.DescribeNodegroup(context.Background(), describeNodegroupInput)
+		_, err := svc.DescribeNodegroup(ctx, describeNodegroupInput)
 		if err != nil {
 			if isEKSNotFound(err) {
 				return nil
@@ -930,20 +947,22 @@ func waitEKSNodeGroupGone(cfg aws.Config, clusterName string, groupName string)
 			return errors.Wrap(err, "failed to describe node group")
 		}
 
-		time.Sleep(10 * time.Second)
+		if err := sleep(ctx, 10*time.Second); err != nil {
+			return err
+		}
 	}
 
 	return errors.New("timed out")
 }
 
-func deleteEKSCluster(cfg aws.Config, clusterName string) error {
+func deleteEKSCluster(ctx context.Context, cfg aws.Config, clusterName string) error {
 	svc := eks.NewFromConfig(cfg)
 
 	deleteClusterInput := &eks.DeleteClusterInput{
 		Name: aws.String(clusterName),
 	}
 
-	_, err := svc.DeleteCluster(context.Background(), deleteClusterInput)
+	_, err := svc.DeleteCluster(ctx, deleteClusterInput)
 	if err != nil {
 		if isEKSNotFound(err) {
 			return nil
@@ -0,0 +1,68 @@
+package grid
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+)
+
+// RecordMergedKubeconfig remembers that the clusters' contexts were merged into the kubeconfig at path,
+// so they're removed from it when the clusters are deleted
+func RecordMergedKubeconfig(store ConfigStore, gridName string, path string, clusterNames ...string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get absolute path of %s", path)
+	}
+
+	unlock, err := lockConfig(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, err := loadConfig(store)
+	if err != nil {
+		return errors.Wrap(err, "failed to load config")
+	}
+
+	for _, gridConfig := range c.GridConfigs {
+		if gridConfig.Name != gridName {
+			continue
+		}
+		for _, clusterConfig := range gridConfig.ClusterConfigs {
+			for _, name := range clusterNames {
+				if clusterConfig.Name == name && !containsString(clusterConfig.MergedKubeconfigs, absPath) {
+					clusterConfig.MergedKubeconfigs = append(clusterConfig.MergedKubeconfigs, absPath)
+				}
+			}
+		}
+	}
+
+	if err := saveConfig(c, store); err != nil {
+		return errors.Wrap(err, "failed to save config")
+	}
+
+	return nil
+}
+
+// mergedKubeconfigPaths returns every kubeconfig the cluster's context may have been merged into
+func mergedKubeconfigPaths(c *types.ClusterConfig) []string {
+	paths := []string{cluster.MergedKubeconfigPath}
+	for _, path := range c.MergedKubeconfigs {
+		if !containsString(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package grid
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+)
+
+// LikeOptions overrides settings of the grid being cloned
+type LikeOptions struct {
+	Region  string
+	Version string
+}
+
+// SpecLike reconstructs a grid spec with the same clusters as an existing grid.
+// Credentials are copied from the grid config, falling back to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY for grids created without them.
+func SpecLike(store ConfigStore, likeName string, name string, opts LikeOptions) (*types.Grid, error) {
+	gridConfig, err := getGridConfig(store, likeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return specFromGridConfig(gridConfig, name, opts)
+}
+
+func specFromGridConfig(gridConfig *types.GridConfig, name string, opts LikeOptions) (*types.Grid, error) {
+	if len(gridConfig.ClusterConfigs) == 0 {
+		return nil, errors.Errorf("grid %s has no clusters", gridConfig.Name)
+	}
+
+	g := &types.Grid{
+		Name: name,
+	}
+
+	for _, clusterConfig := range gridConfig.ClusterConfigs {
+		if clusterConfig.Provider != "aws" {
+			return nil, errors.Errorf("cluster %s has unsupported provider %q", clusterConfig.Name, clusterConfig.Provider)
+		}
+
+		accessKeyID := types.ValueOrValueFrom{ValueFrom: &types.ValueFrom{OSEnv: "AWS_ACCESS_KEY_ID"}}
+		secretAccessKey := types.ValueOrValueFrom{ValueFrom: &types.ValueFrom{OSEnv: "AWS_SECRET_ACCESS_KEY"}}
+		if clusterConfig.AccessKeyID != nil && clusterConfig.SecretAccessKey != nil {
+			accessKeyID = *clusterConfig.AccessKeyID
+			secretAccessKey = *clusterConfig.SecretAccessKey
+		}
+
+		// an existing cluster is shared, not copied, so region and version overrides don't apply
+		if clusterConfig.IsExisting {
+			g.Spec.Clusters = append(g.Spec.Clusters, &types.ClusterSpec{
+				EKS: &types.EKSSpec{
+					ExistingCluster: &types.EKSExistingClusterSpec{
+						AccessKeyID:     accessKeyID,
+						SecretAccessKey: secretAccessKey,
+						ClusterName:     clusterConfig.Name,
+						Region:          clusterConfig.Region,
+					},
+				},
+			})
+			continue
+		}
+
+		newCluster := &types.EKSNewClusterSpec{
+			Description:     clusterConfig.Description,
+			Version:         clusterConfig.Version,
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			Region:          clusterConfig.Region,
+		}
+		if opts.Region != "" {
+			newCluster.Region = opts.Region
+		}
+		if opts.Version != "" {
+			newCluster.Version = opts.Version
+		}
+
+		g.Spec.Clusters = append(g.Spec.Clusters, &types.ClusterSpec{
+			EKS: &types.EKSSpec{
+				NewCluster: newCluster,
+			},
+		})
+	}
+
+	return g, nil
+}
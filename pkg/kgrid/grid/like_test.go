@@ -0,0 +1,119 @@
+package grid
+
+import (
+	"testing"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_specFromGridConfig(t *testing.T) {
+	accessKeyID := types.ValueOrValueFrom{ValueFrom: &types.ValueFrom{OSEnv: "AWS_ACCESS_KEY_ID"}}
+	secretAccessKey := types.ValueOrValueFrom{ValueFrom: &types.ValueFrom{OSEnv: "AWS_SECRET_ACCESS_KEY"}}
+
+	gridConfig := &types.GridConfig{
+		Name: "original",
+		ClusterConfigs: []*types.ClusterConfig{
+			{
+				Name:        "grid-abc",
+				Provider:    "aws",
+				Region:      "us-east-1",
+				Version:     "1.21",
+				Description: "new one",
+			},
+			{
+				Name:       "shared",
+				Provider:   "aws",
+				IsExisting: true,
+				Region:     "us-west-2",
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     LikeOptions
+		expected *types.Grid
+	}{
+		{
+			name: "same shape",
+			expected: &types.Grid{
+				Name: "clone",
+				Spec: types.GridSpec{
+					Clusters: []*types.ClusterSpec{
+						{
+							EKS: &types.EKSSpec{
+								NewCluster: &types.EKSNewClusterSpec{
+									Description:     "new one",
+									Version:         "1.21",
+									AccessKeyID:     accessKeyID,
+									SecretAccessKey: secretAccessKey,
+									Region:          "us-east-1",
+								},
+							},
+						},
+						{
+							EKS: &types.EKSSpec{
+								ExistingCluster: &types.EKSExistingClusterSpec{
+									AccessKeyID:     accessKeyID,
+									SecretAccessKey: secretAccessKey,
+									ClusterName:     "shared",
+									Region:          "us-west-2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "overrides only apply to new clusters",
+			opts: LikeOptions{
+				Region:  "eu-west-1",
+				Version: "1.23",
+			},
+			expected: &types.Grid{
+				Name: "clone",
+				Spec: types.GridSpec{
+					Clusters: []*types.ClusterSpec{
+						{
+							EKS: &types.EKSSpec{
+								NewCluster: &types.EKSNewClusterSpec{
+									Description:     "new one",
+									Version:         "1.23",
+									AccessKeyID:     accessKeyID,
+									SecretAccessKey: secretAccessKey,
+									Region:          "eu-west-1",
+								},
+							},
+						},
+						{
+							EKS: &types.EKSSpec{
+								ExistingCluster: &types.EKSExistingClusterSpec{
+									AccessKeyID:     accessKeyID,
+									SecretAccessKey: secretAccessKey,
+									ClusterName:     "shared",
+									Region:          "us-west-2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			actual, err := specFromGridConfig(gridConfig, "clone", test.opts)
+			req.NoError(err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+
+	_, err := specFromGridConfig(&types.GridConfig{Name: "empty"}, "clone", LikeOptions{})
+	assert.Error(t, err)
+}
@@ -5,11 +5,26 @@ import (
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 )
 
-func List(configFilePath string) ([]*types.GridConfig, error) {
-	c, err := loadConfig(configFilePath)
+func List(store ConfigStore) ([]*types.GridConfig, error) {
+	c, err := loadConfig(store)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read config")
 	}
 
 	return c.GridConfigs, nil
 }
+
+func getGridConfig(store ConfigStore, name string) (*types.GridConfig, error) {
+	gridConfigs, err := List(store)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list grids")
+	}
+
+	for _, gridConfig := range gridConfigs {
+		if gridConfig.Name == name {
+			return gridConfig, nil
+		}
+	}
+
+	return nil, errors.Errorf("grid %s not found", name)
+}
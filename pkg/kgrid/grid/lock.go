@@ -0,0 +1,94 @@
+package grid
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// LockTimeout is how long to wait for another process to release the config lock
+	LockTimeout = 2 * time.Minute
+
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// lockFile takes an advisory lock on path+".lock", which is shared by all processes using the same config.
+// The lock is released by the OS when its holder exits, so a lock file left behind by a process that
+// crashed is never stale. Holders aren't checked for being alive, since processes sharing the config
+// from other containers or hosts can't be seen. The lock file only records the holder for error messages.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create lock dir")
+	}
+
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open lock file")
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "failed to lock file")
+		}
+
+		if locked {
+			if err := writeLockHolder(f); err != nil {
+				unlockFile(f)
+				f.Close()
+				return nil, errors.Wrap(err, "failed to write lock holder")
+			}
+
+			return func() {
+				f.Truncate(0)
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			holder := readLockHolder(f)
+			f.Close()
+			return nil, errors.Errorf("timed out waiting for config lock held by %s", holder)
+		}
+
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// lockHolder identifies this process, by pid and host since the config may be shared between hosts or containers
+func lockHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("pid %d on %s", os.Getpid(), hostname)
+}
+
+func writeLockHolder(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt([]byte(lockHolder()+"\n"), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func readLockHolder(f *os.File) string {
+	b, err := ioutil.ReadAll(f)
+	if err != nil || len(strings.TrimSpace(string(b))) == 0 {
+		return "another process"
+	}
+
+	return strings.TrimSpace(string(b))
+}
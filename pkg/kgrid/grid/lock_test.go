@@ -0,0 +1,143 @@
+//go:build !windows
+
+package grid
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	writerConfigEnv = "KGRID_TEST_WRITER_CONFIG"
+	writerIDEnv     = "KGRID_TEST_WRITER_ID"
+	writerGrids     = 10
+)
+
+// Test_configWriterProcess is run as a subprocess by Test_lockConfigSubprocesses
+func Test_configWriterProcess(t *testing.T) {
+	configFile := os.Getenv(writerConfigEnv)
+	if configFile == "" {
+		t.Skip("only run as a subprocess")
+	}
+
+	req := require.New(t)
+	store := NewFileStore(configFile)
+	id := os.Getenv(writerIDEnv)
+
+	for i := 0; i < writerGrids; i++ {
+		gridName := fmt.Sprintf("grid-%s-%d", id, i)
+		req.NoError(addGridToConfig(store, gridName))
+		req.NoError(addClusterToConfig(store, gridName, &types.ClusterConfig{
+			Name: fmt.Sprintf("cluster-%s-%d", id, i),
+		}))
+	}
+}
+
+func Test_lockConfigSubprocesses(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	configFile := filepath.Join(tmpDir, "config")
+
+	writers := 4
+	wg := sync.WaitGroup{}
+	outputs := make([][]byte, writers)
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^Test_configWriterProcess$")
+			cmd.Env = append(os.Environ(),
+				fmt.Sprintf("%s=%s", writerConfigEnv, configFile),
+				fmt.Sprintf("%s=%d", writerIDEnv, i),
+			)
+			outputs[i], errs[i] = cmd.CombinedOutput()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range errs {
+		req.NoError(errs[i], string(outputs[i]))
+	}
+
+	cfg, err := NewFileStore(configFile).Load()
+	req.NoError(err)
+
+	clusters := map[string]string{}
+	for _, g := range cfg.GridConfigs {
+		for _, c := range g.ClusterConfigs {
+			clusters[g.Name] = c.Name
+		}
+	}
+
+	req.Len(cfg.GridConfigs, writers*writerGrids)
+	for i := 0; i < writers; i++ {
+		for j := 0; j < writerGrids; j++ {
+			assert.Equal(t, fmt.Sprintf("cluster-%d-%d", i, j), clusters[fmt.Sprintf("grid-%d-%d", i, j)])
+		}
+	}
+}
+
+func Test_lockFileLeftBehind(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	configFile := filepath.Join(tmpDir, "config")
+
+	// a process that exited while holding the lock leaves the file behind, but not the lock
+	err = ioutil.WriteFile(configFile+".lock", []byte("pid 1 on another-host\n"), 0600)
+	req.NoError(err)
+
+	unlock, err := lockFile(configFile)
+	req.NoError(err)
+
+	b, err := ioutil.ReadFile(configFile + ".lock")
+	req.NoError(err)
+	assert.Equal(t, lockHolder()+"\n", string(b))
+
+	unlock()
+}
+
+func Test_lockFileHeldByDeadLookingPid(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+	configFile := filepath.Join(tmpDir, "config")
+
+	// get the pid of a process that has exited, as a holder in another pid namespace would look
+	cmd := exec.Command("true")
+	req.NoError(cmd.Run())
+	holder := fmt.Sprintf("pid %d on another-host", cmd.Process.Pid)
+
+	f, err := os.OpenFile(configFile+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	req.NoError(err)
+	defer f.Close()
+	locked, err := tryLockFile(f)
+	req.NoError(err)
+	req.True(locked)
+	_, err = f.WriteString(holder + "\n")
+	req.NoError(err)
+
+	defer func(timeout time.Duration) { LockTimeout = timeout }(LockTimeout)
+	LockTimeout = 300 * time.Millisecond
+
+	_, err = lockFile(configFile)
+	req.EqualError(err, "timed out waiting for config lock held by "+holder)
+}
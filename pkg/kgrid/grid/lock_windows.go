@@ -0,0 +1,27 @@
+//go:build windows
+
+package grid
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func tryLockFile(f *os.File) (bool, error) {
+	ol := new(windows.Overlapped)
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK | windows.LOCKFILE_FAIL_IMMEDIATELY)
+	err := windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, 1, 0, ol)
+	if err == windows.ERROR_LOCK_VIOLATION {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func unlockFile(f *os.File) {
+	ol := new(windows.Overlapped)
+	windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, ol)
+}
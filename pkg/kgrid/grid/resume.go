@@ -0,0 +1,69 @@
+package grid
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/logger"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
+)
+
+// Resume finishes creating the clusters in a grid whose create was interrupted,
+// skipping the steps that were already checkpointed in the config.
+// Like Create, clusters that still don't finish are rolled back unless opts.KeepOnFailure is set.
+func Resume(ctx context.Context, store ConfigStore, gridName string, opts CreateOptions, log logger.Logger) error {
+	gridConfig, err := getGridConfig(store, gridName)
+	if err != nil {
+		return err
+	}
+
+	clusterConfigs := []*types.ClusterConfig{}
+	for _, c := range gridConfig.ClusterConfigs {
+		if c.Creating {
+			clusterConfigs = append(clusterConfigs, c)
+		}
+	}
+	if len(clusterConfigs) == 0 {
+		log.Info("Grid %s has no clusters to resume", gridName)
+		return nil
+	}
+
+	tasks := []parallel.Task[struct{}]{}
+	for _, c := range clusterConfigs {
+		c := c
+		tasks = append(tasks, func(ctx context.Context) (struct{}, error) {
+			clusterLog := log.ForCluster(c.Name)
+			clusterLog.Info("Resuming create of EKS cluster %s", c.Name)
+			if err := buildNewEKSCluster(ctx, gridName, c, store, clusterLog); err != nil {
+				clusterLog.Error(err)
+				return struct{}{}, err
+			}
+			return struct{}{}, nil
+		})
+	}
+
+	resumeErrors := []error{}
+	for _, result := range parallel.Run(ctx, createConcurrency, tasks) {
+		if result.Err != nil {
+			resumeErrors = append(resumeErrors, errors.Wrapf(result.Err, "resume cluster %s", clusterConfigs[result.Index].Name))
+		}
+	}
+	if (len(resumeErrors) > 0 || ctx.Err() != nil) && !opts.KeepOnFailure {
+		if err := rollbackUnfinishedClusters(gridName, store, log); err != nil {
+			resumeErrors = append(resumeErrors, errors.Wrap(err, "failed to roll back resume"))
+		}
+		if err := removeGridIfEmpty(gridName, store); err != nil {
+			resumeErrors = append(resumeErrors, errors.Wrap(err, "failed to remove empty grid from config"))
+		}
+	}
+	if ctx.Err() != nil {
+		resumeErrors = append(resumeErrors, ctx.Err())
+	}
+	if len(resumeErrors) > 0 {
+		return &kerrors.MultiError{Errors: resumeErrors}
+	}
+
+	return nil
+}
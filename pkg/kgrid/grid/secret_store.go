@@ -0,0 +1,248 @@
+package grid
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// GridConfigLabel marks secrets that hold a grid config
+	GridConfigLabel = "kgrid.replicated.com/grid-config"
+	// GridNameAnnotation records the grid name, which may not be a valid label value
+	GridNameAnnotation = "kgrid.replicated.com/grid-name"
+
+	gridConfigKey  = "grid.yaml"
+	configLockName = "kgrid-config-lock"
+	configLockTTL  = 60 * time.Second
+)
+
+// SecretStore stores each grid config, including cluster kubeconfigs, in a Secret in a namespace,
+// so grids can be shared between users. The operator's test pods use the store in the operator namespace,
+// so the grids they create can be seen with --store=k8s.
+// The lock is a Lease in the same namespace.
+type SecretStore struct {
+	Client    kubernetes.Interface
+	Namespace string
+
+	holderIdentity string
+}
+
+func NewSecretStore(client kubernetes.Interface, namespace string) *SecretStore {
+	hostname, _ := os.Hostname()
+
+	return &SecretStore{
+		Client:         client,
+		Namespace:      namespace,
+		holderIdentity: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+func (s *SecretStore) Load() (*types.GridsConfig, error) {
+	ctx := context.Background()
+
+	secrets, err := s.Client.CoreV1().Secrets(s.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", GridConfigLabel),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list grid secrets")
+	}
+
+	items := secrets.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.Before(&items[j].CreationTimestamp)
+	})
+
+	cfg := emptyConfig()
+	for _, secret := range items {
+		gridConfig := types.GridConfig{}
+		if err := yaml.Unmarshal(secret.Data[gridConfigKey], &gridConfig); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal grid secret %s", secret.Name)
+		}
+		cfg.GridConfigs = append(cfg.GridConfigs, &gridConfig)
+	}
+
+	return cfg, nil
+}
+
+func (s *SecretStore) Save(cfg *types.GridsConfig) error {
+	ctx := context.Background()
+
+	existing, err := s.Client.CoreV1().Secrets(s.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", GridConfigLabel),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list grid secrets")
+	}
+
+	keep := map[string]bool{}
+	for _, gridConfig := range cfg.GridConfigs {
+		secretName := gridSecretName(gridConfig.Name)
+		keep[secretName] = true
+
+		if err := s.saveGrid(ctx, secretName, gridConfig); err != nil {
+			return errors.Wrapf(err, "failed to save grid %s", gridConfig.Name)
+		}
+	}
+
+	for _, secret := range existing.Items {
+		if keep[secret.Name] {
+			continue
+		}
+		err := s.Client.CoreV1().Secrets(s.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete grid secret %s", secret.Name)
+		}
+	}
+
+	return nil
+}
+
+func (s *SecretStore) saveGrid(ctx context.Context, secretName string, gridConfig *types.GridConfig) error {
+	b, err := yaml.Marshal(gridConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal grid config")
+	}
+
+	secret, err := s.Client.CoreV1().Secrets(s.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: s.Namespace,
+				Labels: map[string]string{
+					GridConfigLabel: "true",
+				},
+				Annotations: map[string]string{
+					GridNameAnnotation: gridConfig.Name,
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				gridConfigKey: b,
+			},
+		}
+		if _, err := s.Client.CoreV1().Secrets(s.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to create secret")
+		}
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get secret")
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[gridConfigKey] = b
+	if _, err := s.Client.CoreV1().Secrets(s.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update secret")
+	}
+
+	return nil
+}
+
+// Lock acquires the config lease, taking it over if the holder let it expire
+func (s *SecretStore) Lock() (func(), error) {
+	ctx := context.Background()
+	leases := s.Client.CoordinationV1().Leases(s.Namespace)
+
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		acquired, err := s.tryAcquireLease(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to acquire config lease")
+		}
+
+		if acquired {
+			return func() {
+				lease, err := leases.Get(ctx, configLockName, metav1.GetOptions{})
+				if err != nil || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != s.holderIdentity {
+					return
+				}
+				leases.Delete(ctx, configLockName, metav1.DeleteOptions{
+					Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+				})
+			}, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("timed out waiting for config lease")
+		}
+
+		time.Sleep(lockPollInterval)
+	}
+}
+
+func (s *SecretStore) tryAcquireLease(ctx context.Context) (bool, error) {
+	leases := s.Client.CoordinationV1().Leases(s.Namespace)
+
+	now := metav1.NewMicroTime(time.Now())
+	ttl := int32(configLockTTL.Seconds())
+	spec := coordinationv1.LeaseSpec{
+		HolderIdentity:       &s.holderIdentity,
+		LeaseDurationSeconds: &ttl,
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+
+	lease, err := leases.Get(ctx, configLockName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		_, err := leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configLockName,
+				Namespace: s.Namespace,
+			},
+			Spec: spec,
+		}, metav1.CreateOptions{})
+		if kuberneteserrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return err == nil, err
+	} else if err != nil {
+		return false, err
+	}
+
+	if !isLeaseExpired(lease) {
+		return false, nil
+	}
+
+	// the update fails with a conflict if someone else took it over first
+	lease.Spec = spec
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if kuberneteserrors.IsConflict(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func isLeaseExpired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	expires := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return time.Now().After(expires)
+}
+
+// gridSecretName returns a valid secret name for the grid, falling back to a hash of the name
+func gridSecretName(gridName string) string {
+	name := fmt.Sprintf("kgrid-%s", gridName)
+	if len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("kgrid-%x", sha256.Sum256([]byte(gridName)))[:38]
+}
@@ -0,0 +1,98 @@
+package grid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_SecretStore(t *testing.T) {
+	req := require.New(t)
+
+	client := fake.NewSimpleClientset()
+	store := NewSecretStore(client, "kgrid-system")
+
+	cfg, err := store.Load()
+	req.NoError(err)
+	assert.Equal(t, emptyConfig(), cfg)
+
+	req.NoError(store.Save(testGridsConfig))
+
+	cfg, err = store.Load()
+	req.NoError(err)
+	assert.Equal(t, testGridsConfig, cfg)
+
+	secret, err := client.CoreV1().Secrets("kgrid-system").Get(context.Background(), "kgrid-grid", metav1.GetOptions{})
+	req.NoError(err)
+	assert.Equal(t, "true", secret.Labels[GridConfigLabel])
+	assert.Equal(t, "grid", secret.Annotations[GridNameAnnotation])
+
+	// grids that are removed from the config have their secret deleted
+	req.NoError(store.Save(&types.GridsConfig{
+		GridConfigs: []*types.GridConfig{
+			{Name: "Not A Valid Name"},
+		},
+	}))
+
+	cfg, err = store.Load()
+	req.NoError(err)
+	req.Len(cfg.GridConfigs, 1)
+	assert.Equal(t, "Not A Valid Name", cfg.GridConfigs[0].Name)
+
+	secrets, err := client.CoreV1().Secrets("kgrid-system").List(context.Background(), metav1.ListOptions{})
+	req.NoError(err)
+	req.Len(secrets.Items, 1)
+	assert.NotEqual(t, "kgrid-grid", secrets.Items[0].Name)
+}
+
+func Test_SecretStoreLock(t *testing.T) {
+	req := require.New(t)
+
+	defer func(timeout time.Duration) { LockTimeout = timeout }(LockTimeout)
+	LockTimeout = 300 * time.Millisecond
+
+	client := fake.NewSimpleClientset()
+	first := NewSecretStore(client, "kgrid-system")
+	second := NewSecretStore(client, "kgrid-system")
+	second.holderIdentity = "second"
+
+	unlock, err := first.Lock()
+	req.NoError(err)
+
+	_, err = second.Lock()
+	req.Error(err)
+
+	unlock()
+
+	unlockSecond, err := second.Lock()
+	req.NoError(err)
+	unlockSecond()
+
+	// an expired lease is taken over
+	expired := metav1.NewMicroTime(time.Now().Add(-2 * configLockTTL))
+	ttl := int32(configLockTTL.Seconds())
+	holder := "crashed"
+	_, err = client.CoordinationV1().Leases("kgrid-system").Create(context.Background(), &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configLockName,
+			Namespace: "kgrid-system",
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &ttl,
+			RenewTime:            &expired,
+		},
+	}, metav1.CreateOptions{})
+	req.NoError(err)
+
+	unlock, err = first.Lock()
+	req.NoError(err)
+	unlock()
+}
@@ -0,0 +1,236 @@
+package grid
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigStore loads and saves the grids config.
+// The config contains admin kubeconfigs for every cluster, so stores must not leave it readable by other users.
+// Lock is held around load/modify/save, and must exclude other processes using the same store.
+type ConfigStore interface {
+	Load() (*types.GridsConfig, error)
+	Save(cfg *types.GridsConfig) error
+	Lock() (unlock func(), err error)
+}
+
+// FileStore stores the config as plain yaml in a file only readable by the current user
+type FileStore struct {
+	Path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		Path: path,
+	}
+}
+
+func (s *FileStore) Load() (*types.GridsConfig, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return emptyConfig(), nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	return unmarshalConfig(b)
+}
+
+func (s *FileStore) Lock() (func(), error) {
+	return lockFile(s.Path)
+}
+
+func (s *FileStore) Save(cfg *types.GridsConfig) error {
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config")
+	}
+
+	if err := writeFileAtomic(s.Path, b); err != nil {
+		return errors.Wrap(err, "failed to write config file")
+	}
+
+	return nil
+}
+
+// EncryptedFileStore stores the config in a file encrypted with age.
+// An unencrypted config file is still loaded, so an existing config is encrypted the next time it's saved.
+type EncryptedFileStore struct {
+	Path       string
+	Identities []age.Identity
+	Recipients []age.Recipient
+}
+
+// NewPassphraseStore returns a store that encrypts the config with a passphrase
+func NewPassphraseStore(path string, passphrase string) (*EncryptedFileStore, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is required")
+	}
+
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create scrypt recipient")
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create scrypt identity")
+	}
+
+	return &EncryptedFileStore{
+		Path:       path,
+		Identities: []age.Identity{identity},
+		Recipients: []age.Recipient{recipient},
+	}, nil
+}
+
+// NewIdentityFileStore returns a store that encrypts the config to the X25519 identities in an age identity file
+func NewIdentityFileStore(path string, identityFile string) (*EncryptedFileStore, error) {
+	f, err := os.Open(identityFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open identity file")
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse identity file")
+	}
+
+	recipients := []age.Recipient{}
+	for _, identity := range identities {
+		x25519Identity, ok := identity.(*age.X25519Identity)
+		if !ok {
+			return nil, errors.Errorf("unsupported identity type %T", identity)
+		}
+		recipients = append(recipients, x25519Identity.Recipient())
+	}
+
+	return &EncryptedFileStore{
+		Path:       path,
+		Identities: identities,
+		Recipients: recipients,
+	}, nil
+}
+
+func (s *EncryptedFileStore) Load() (*types.GridsConfig, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return emptyConfig(), nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	if !strings.HasPrefix(string(b), armor.Header) {
+		return unmarshalConfig(b)
+	}
+
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(b)), s.Identities...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt config file")
+	}
+
+	decrypted, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read decrypted config")
+	}
+
+	return unmarshalConfig(decrypted)
+}
+
+func (s *EncryptedFileStore) Lock() (func(), error) {
+	return lockFile(s.Path)
+}
+
+func (s *EncryptedFileStore) Save(cfg *types.GridsConfig) error {
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config")
+	}
+
+	var buf bytes.Buffer
+	armorWriter := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armorWriter, s.Recipients...)
+	if err != nil {
+		return errors.Wrap(err, "failed to create encrypted writer")
+	}
+	if _, err := w.Write(b); err != nil {
+		return errors.Wrap(err, "failed to encrypt config")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "failed to finish encrypting config")
+	}
+	if err := armorWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to finish armoring config")
+	}
+
+	if err := writeFileAtomic(s.Path, buf.Bytes()); err != nil {
+		return errors.Wrap(err, "failed to write config file")
+	}
+
+	return nil
+}
+
+func emptyConfig() *types.GridsConfig {
+	return &types.GridsConfig{
+		GridConfigs: []*types.GridConfig{},
+	}
+}
+
+func unmarshalConfig(b []byte) (*types.GridsConfig, error) {
+	cfg := types.GridsConfig{}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config")
+	}
+
+	return &cfg, nil
+}
+
+// writeFileAtomic writes to a temp file in the same dir and renames it over path,
+// so readers never see a partial config and the file is always 0600
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "failed to create config dir")
+	}
+	if err := restrictConfigDir(dir); err != nil {
+		return errors.Wrap(err, "failed to chmod config dir")
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp file")
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to chmod temp file")
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to write temp file")
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to sync temp file")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temp file")
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return errors.Wrap(err, "failed to rename temp file")
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package grid
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testGridsConfig = &types.GridsConfig{
+	GridConfigs: []*types.GridConfig{
+		{
+			Name: "grid",
+			ClusterConfigs: []*types.ClusterConfig{
+				{
+					Name:       "cluster",
+					Provider:   "aws",
+					Region:     "us-west-1",
+					Kubeconfig: "secret-kubeconfig",
+				},
+			},
+		},
+	},
+}
+
+func Test_FileStore(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, ".grid", "config")
+	store := NewFileStore(path)
+
+	cfg, err := store.Load()
+	req.NoError(err)
+	assert.Equal(t, emptyConfig(), cfg)
+
+	req.NoError(store.Save(testGridsConfig))
+
+	dirInfo, err := os.Stat(filepath.Dir(path))
+	req.NoError(err)
+	assert.Equal(t, os.FileMode(0700), dirInfo.Mode().Perm())
+
+	// an existing world readable dir is made private
+	req.NoError(os.Chmod(filepath.Dir(path), 0755))
+	req.NoError(store.Save(testGridsConfig))
+
+	dirInfo, err = os.Stat(filepath.Dir(path))
+	req.NoError(err)
+	assert.Equal(t, os.FileMode(0700), dirInfo.Mode().Perm())
+
+	// an existing world readable file is replaced
+	req.NoError(os.Chmod(path, 0644))
+	req.NoError(store.Save(testGridsConfig))
+
+	info, err := os.Stat(path)
+	req.NoError(err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// no temp files are left behind
+	entries, err := ioutil.ReadDir(filepath.Dir(path))
+	req.NoError(err)
+	req.Len(entries, 1)
+
+	cfg, err = store.Load()
+	req.NoError(err)
+	assert.Equal(t, testGridsConfig, cfg)
+}
+
+func Test_EncryptedFileStore(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	identity, err := age.GenerateX25519Identity()
+	req.NoError(err)
+	identityFile := filepath.Join(tmpDir, "identity")
+	req.NoError(ioutil.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600))
+
+	path := filepath.Join(tmpDir, "config")
+
+	// start with a plain config, which is encrypted on the next save
+	req.NoError(NewFileStore(path).Save(testGridsConfig))
+
+	store, err := NewIdentityFileStore(path, identityFile)
+	req.NoError(err)
+
+	cfg, err := store.Load()
+	req.NoError(err)
+	assert.Equal(t, testGridsConfig, cfg)
+
+	req.NoError(store.Save(cfg))
+
+	b, err := ioutil.ReadFile(path)
+	req.NoError(err)
+	assert.True(t, strings.HasPrefix(string(b), "-----BEGIN AGE ENCRYPTED FILE-----"))
+	assert.NotContains(t, string(b), "secret-kubeconfig")
+
+	cfg, err = store.Load()
+	req.NoError(err)
+	assert.Equal(t, testGridsConfig, cfg)
+
+	// a different identity can't read it
+	otherIdentity, err := age.GenerateX25519Identity()
+	req.NoError(err)
+	otherStore := &EncryptedFileStore{
+		Path:       path,
+		Identities: []age.Identity{otherIdentity},
+		Recipients: []age.Recipient{otherIdentity.Recipient()},
+	}
+	_, err = otherStore.Load()
+	req.Error(err)
+}
+
+func Test_PassphraseStore(t *testing.T) {
+	req := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "")
+	req.NoError(err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "config")
+
+	store, err := NewPassphraseStore(path, "correct horse")
+	req.NoError(err)
+	req.NoError(store.Save(testGridsConfig))
+
+	cfg, err := store.Load()
+	req.NoError(err)
+	assert.Equal(t, testGridsConfig, cfg)
+
+	wrongStore, err := NewPassphraseStore(path, "battery staple")
+	req.NoError(err)
+	_, err = wrongStore.Load()
+	req.Error(err)
+
+	_, err = NewPassphraseStore(path, "")
+	req.Error(err)
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package grid
+
+import (
+	"os"
+	"syscall"
+)
+
+// restrictConfigDir makes a config dir created by an older version, or by hand, private again.
+// Shared dirs owned by someone else are left alone.
+func restrictConfigDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) != os.Getuid() || info.Mode().Perm() == 0700 {
+		return nil
+	}
+
+	return os.Chmod(dir, 0700)
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package grid
+
+// restrictConfigDir is a no-op on windows, where access is controlled by ACLs rather than the mode
+func restrictConfigDir(dir string) error {
+	return nil
+}
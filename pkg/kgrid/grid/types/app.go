@@ -13,6 +13,8 @@ type Application struct {
 
 type ApplicationSpec struct {
 	KOTSApplicationSpec *KOTSApplicationSpec `json:"kots,omitempty"`
+	Wait                *WaitSpec            `json:"wait,omitempty"`
+	SupportBundle       *SupportBundleSpec   `json:"supportBundle,omitempty"`
 }
 
 type KOTSApplicationSpec struct {
@@ -24,3 +26,58 @@ type KOTSApplicationSpec struct {
 	Namespace      string                        `json:"namespace,omitempty"`
 	ConfigValues   *kotsv1beta1.ConfigValuesSpec `json:"configValues,omitempty"`
 }
+
+// WaitSpec controls how long a deploy waits for the application, and what counts as ready
+type WaitSpec struct {
+	InstallTimeout *metav1.Duration `json:"installTimeout,omitempty"`
+	ReadyTimeout   *metav1.Duration `json:"readyTimeout,omitempty"`
+	StatusTimeout  *metav1.Duration `json:"statusTimeout,omitempty"`
+	PollInterval   *metav1.Duration `json:"pollInterval,omitempty"`
+	Backoff        *BackoffSpec     `json:"backoff,omitempty"`
+	Ready          *ReadyCriteria   `json:"ready,omitempty"`
+}
+
+// BackoffSpec grows the poll interval by factor after each poll, up to maxInterval
+type BackoffSpec struct {
+	Factor      float64          `json:"factor,omitempty"`
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+}
+
+type ReadyCriteria struct {
+	// States are the application states that are considered ready. Defaults to "ready".
+	States []string `json:"states,omitempty"`
+	// TolerateDegraded lists resources that can be degraded without failing the ready check
+	TolerateDegraded []ResourceSelector `json:"tolerateDegraded,omitempty"`
+}
+
+// ResourceSelector matches a resource by name, and optionally kind and namespace
+type ResourceSelector struct {
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+const (
+	SupportBundleCollectAlways    = "always"
+	SupportBundleCollectOnFailure = "onFailure"
+	SupportBundleCollectNever     = "never"
+)
+
+// SupportBundleSpec controls when a support bundle is collected from each cluster after a deploy, and what's in it
+type SupportBundleSpec struct {
+	// Collect is always, onFailure, or never. Defaults to onFailure.
+	Collect string `json:"collect,omitempty"`
+	// Specs are the troubleshoot SupportBundle specs to collect. Defaults to https://kots.io.
+	Specs []SupportBundleSource `json:"specs,omitempty"`
+	// Redactors are troubleshoot Redactor specs, applied as well as the default redactors
+	Redactors []SupportBundleSource `json:"redactors,omitempty"`
+}
+
+// SupportBundleSource is where to find a troubleshoot spec. Exactly one of its fields should be set.
+type SupportBundleSource struct {
+	// Inline is the spec's yaml
+	Inline string `json:"inline,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// App uses the support bundle spec that KOTS stores in the cluster for the deployed app
+	App bool `json:"app,omitempty"`
+}
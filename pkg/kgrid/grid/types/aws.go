@@ -1,12 +1,12 @@
 package types
 
 type AWSVPC struct {
-	ID                string
-	SecurityGroupIDs  []string
-	PrivateSubnetIDs  []string
-	PublicSubnetID    string
-	InternetGatewayID string
-	EIPAllocationID   string
-	NATGatewayID      string
-	RoleArn           string
+	ID                string   `json:"id"`
+	SecurityGroupIDs  []string `json:"securityGroupIds,omitempty"`
+	PrivateSubnetIDs  []string `json:"privateSubnetIds,omitempty"`
+	PublicSubnetID    string   `json:"publicSubnetId,omitempty"`
+	InternetGatewayID string   `json:"internetGatewayId,omitempty"`
+	EIPAllocationID   string   `json:"eipAllocationId,omitempty"`
+	NATGatewayID      string   `json:"natGatewayId,omitempty"`
+	RoleArn           string   `json:"roleArn,omitempty"`
 }
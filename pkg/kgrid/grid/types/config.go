@@ -1,5 +1,9 @@
 package types
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type GridsConfig struct {
 	GridConfigs []*GridConfig `json:"grids,omitempty"`
 }
@@ -10,11 +14,49 @@ type GridConfig struct {
 }
 
 type ClusterConfig struct {
-	Name        string `json:"name"`
-	Provider    string `json:"provider"`
-	IsExisting  bool   `json:"isExisting"`
-	Region      string `json:"region"`
-	Kubeconfig  string `json:"kubeconfig,omitempty"`
-	Description string `json:"description,omitempty"`
-	Version     string `json:"version,omitempty"`
+	Name        string       `json:"name"`
+	Provider    string       `json:"provider"`
+	IsExisting  bool         `json:"isExisting"`
+	Region      string       `json:"region"`
+	Kubeconfig  string       `json:"kubeconfig,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Version     string       `json:"version,omitempty"`
+	CreatedAt   *metav1.Time `json:"createdAt,omitempty"`
+
+	// AccessKeyID and SecretAccessKey are copied from the grid spec, so an env var reference stays a reference
+	AccessKeyID     *ValueOrValueFrom `json:"accessKeyId,omitempty"`
+	SecretAccessKey *ValueOrValueFrom `json:"secretAccessKey,omitempty"`
+	VPC             *AWSVPC           `json:"vpc,omitempty"`
+	ControlPlaneARN string            `json:"controlPlaneArn,omitempty"`
+	NodeGroupName   string            `json:"nodeGroupName,omitempty"`
+	NodeGroupARN    string            `json:"nodeGroupArn,omitempty"`
+
+	// Creating is set until every create step has completed
+	Creating       bool                `json:"creating,omitempty"`
+	CompletedSteps []ClusterCreateStep `json:"completedSteps,omitempty"`
+
+	// MergedKubeconfigs are the files that kubeconfig --merge added the cluster's context to, so delete can remove it
+	MergedKubeconfigs []string `json:"mergedKubeconfigs,omitempty"`
+}
+
+// ClusterCreateStep is a checkpoint in creating a new cluster, recorded so an interrupted create can resume
+type ClusterCreateStep string
+
+const (
+	ClusterCreateStepVPC          ClusterCreateStep = "vpc"
+	ClusterCreateStepControlPlane ClusterCreateStep = "controlPlane"
+	ClusterCreateStepNodeGroup    ClusterCreateStep = "nodeGroup"
+	ClusterCreateStepKubeconfig   ClusterCreateStep = "kubeconfig"
+	ClusterCreateStepAuthMap      ClusterCreateStep = "authMap"
+	ClusterCreateStepNodes        ClusterCreateStep = "nodes"
+	ClusterCreateStepStorageClass ClusterCreateStep = "storageClass"
+)
+
+func (c *ClusterConfig) HasCompletedStep(step ClusterCreateStep) bool {
+	for _, s := range c.CompletedSteps {
+		if s == step {
+			return true
+		}
+	}
+	return false
 }
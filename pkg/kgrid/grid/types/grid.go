@@ -36,7 +36,25 @@ type EKSNewClusterSpec struct {
 }
 
 type LoggerSpec struct {
+	// Slack is sent to as well as the console when Sinks is empty
 	Slack *SlackLoggerSpec `json:"slack,omitempty"`
+	// Sinks replaces the default console and Slack loggers
+	Sinks []LoggerSinkSpec `json:"sinks,omitempty"`
+}
+
+// LoggerSinkSpec is one destination for logs. Exactly one of its destinations should be set.
+type LoggerSinkSpec struct {
+	// Level is the least severe level sent to the sink: debug, info, or error. Defaults to info.
+	Level   string             `json:"level,omitempty"`
+	Console *ConsoleLoggerSpec `json:"console,omitempty"`
+	Slack   *SlackLoggerSpec   `json:"slack,omitempty"`
+	Webhook *WebhookLoggerSpec `json:"webhook,omitempty"`
+	Teams   *TeamsLoggerSpec   `json:"teams,omitempty"`
+}
+
+type ConsoleLoggerSpec struct {
+	// Format is terminal, json, or auto. Defaults to --log-format.
+	Format string `json:"format,omitempty"`
 }
 
 type SlackLoggerSpec struct {
@@ -44,6 +62,18 @@ type SlackLoggerSpec struct {
 	Channel string           `json:"channel,omitempty"`
 }
 
+// WebhookLoggerSpec POSTs each log event as JSON to a URL
+type WebhookLoggerSpec struct {
+	URL ValueOrValueFrom `json:"url"`
+	// Secret, if set, signs each request body with HMAC-SHA256 in the X-Kgrid-Signature header
+	Secret ValueOrValueFrom `json:"secret,omitempty"`
+}
+
+// TeamsLoggerSpec posts log events as cards to a Microsoft Teams incoming webhook
+type TeamsLoggerSpec struct {
+	URL ValueOrValueFrom `json:"url"`
+}
+
 func (c ClusterSpec) GetNameForLogging() string {
 	if c.EKS != nil {
 		if c.EKS.ExistingCluster != nil {
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/app"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/kubectl"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultTimeout is how long each cluster has to answer before it's reported as unreachable
+const DefaultTimeout = 10 * time.Second
+
+const (
+	StatusCreating    = "Creating"
+	StatusUnreachable = "Unreachable"
+	StatusNotReady    = "NotReady"
+	StatusReady       = "Ready"
+)
+
+// ClusterHealth is what a cluster reported when it was checked, along with what kgrid knows about it
+type ClusterHealth struct {
+	Name       string       `json:"name"`
+	Provider   string       `json:"provider"`
+	Region     string       `json:"region"`
+	IsExisting bool         `json:"isExisting"`
+	Creating   bool         `json:"creating,omitempty"`
+	CreatedAt  *metav1.Time `json:"createdAt,omitempty"`
+
+	Reachable     bool   `json:"reachable"`
+	ServerVersion string `json:"serverVersion,omitempty"`
+	Nodes         int    `json:"nodes"`
+	ReadyNodes    int    `json:"readyNodes"`
+	Error         string `json:"error,omitempty"`
+
+	Apps      []app.DeployedApp `json:"apps,omitempty"`
+	AppsError string            `json:"appsError,omitempty"`
+}
+
+// Status summarizes the health as one of the Status constants
+func (h *ClusterHealth) Status() string {
+	if h.Creating {
+		return StatusCreating
+	}
+	if !h.Reachable {
+		return StatusUnreachable
+	}
+	if h.Nodes == 0 || h.ReadyNodes < h.Nodes {
+		return StatusNotReady
+	}
+	return StatusReady
+}
+
+type Options struct {
+	// Timeout limits each round of requests to a cluster
+	Timeout time.Duration
+	// KOTSBinary returns the kots binary used to list apps. Apps aren't listed if it's nil.
+	KOTSBinary func(ctx context.Context) (string, error)
+}
+
+// CheckAll checks every cluster at the same time, so a dead cluster only delays the result by the timeout
+func CheckAll(ctx context.Context, gridName string, clusterConfigs []*types.ClusterConfig, opts Options) []*ClusterHealth {
+	// the binary is shared by all clusters, so only fetch it once
+	if opts.KOTSBinary != nil {
+		getKOTSBinary := opts.KOTSBinary
+		var once sync.Once
+		var path string
+		var err error
+		opts.KOTSBinary = func(ctx context.Context) (string, error) {
+			once.Do(func() {
+				path, err = getKOTSBinary(ctx)
+			})
+			return path, err
+		}
+	}
+
+	tasks := []parallel.Task[*ClusterHealth]{}
+	for _, c := range clusterConfigs {
+		c := c
+		tasks = append(tasks, func(ctx context.Context) (*ClusterHealth, error) {
+			return Check(ctx, gridName, c, opts), nil
+		})
+	}
+
+	results := []*ClusterHealth{}
+	for _, result := range parallel.Run(ctx, len(tasks), tasks) {
+		h := result.Value
+		if h == nil {
+			h = newClusterHealth(clusterConfigs[result.Index])
+			h.Error = result.Err.Error()
+		}
+		results = append(results, h)
+	}
+
+	return results
+}
+
+// Check asks the cluster for its version, nodes and apps.
+// Clusters that are still being created aren't contacted.
+func Check(ctx context.Context, gridName string, c *types.ClusterConfig, opts Options) *ClusterHealth {
+	h := newClusterHealth(c)
+	if c.Creating {
+		return h
+	}
+
+	clients, err := cluster.GetClients(gridName, c)
+	if err != nil {
+		h.Error = err.Error()
+		return h
+	}
+
+	check(ctx, clients, h, opts)
+	return h
+}
+
+func newClusterHealth(c *types.ClusterConfig) *ClusterHealth {
+	return &ClusterHealth{
+		Name:       c.Name,
+		Provider:   c.Provider,
+		Region:     c.Region,
+		IsExisting: c.IsExisting,
+		Creating:   c.Creating,
+		CreatedAt:  c.CreatedAt,
+	}
+}
+
+type clusterInfo struct {
+	serverVersion  string
+	nodes          int
+	readyNodes     int
+	kotsNamespaces []string
+}
+
+func check(ctx context.Context, clients *cluster.Clients, h *ClusterHealth, opts Options) {
+	infoCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	info, err := getClusterInfo(infoCtx, clients)
+	err = timeoutError(infoCtx, err, opts.Timeout)
+	cancel()
+	h.Reachable = info.serverVersion != ""
+	h.ServerVersion = info.serverVersion
+	h.Nodes = info.nodes
+	h.ReadyNodes = info.readyNodes
+	if err != nil {
+		h.Error = err.Error()
+		return
+	}
+
+	if opts.KOTSBinary == nil || len(info.kotsNamespaces) == 0 {
+		return
+	}
+
+	// fetching the binary isn't limited by the timeout, it's not the cluster that's slow
+	pathToKOTSBinary, err := opts.KOTSBinary(ctx)
+	if err != nil {
+		h.AppsError = errors.Wrap(err, "failed to get kots binary").Error()
+		return
+	}
+
+	appsCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	apps, err := app.ListDeployedApps(appsCtx, clients, info.kotsNamespaces, pathToKOTSBinary)
+	if err := timeoutError(appsCtx, err, opts.Timeout); err != nil {
+		h.AppsError = err.Error()
+		return
+	}
+	h.Apps = apps
+}
+
+// getClusterInfo returns as much as it got before the first error
+func getClusterInfo(ctx context.Context, clients *cluster.Clients) (clusterInfo, error) {
+	info := clusterInfo{}
+
+	serverVersion, err := kubectl.ServerVersion(ctx, clients)
+	if err != nil {
+		return info, err
+	}
+	info.serverVersion = serverVersion
+
+	nodes, err := kubectl.GetNodes(ctx, clients)
+	if err != nil {
+		return info, err
+	}
+	info.nodes = len(nodes.Items)
+	for _, n := range nodes.Items {
+		if kubectl.IsNodeReady(n) {
+			info.readyNodes++
+		}
+	}
+
+	kotsNamespaces, err := app.FindKOTSNamespaces(ctx, clients)
+	if err != nil {
+		return info, err
+	}
+	info.kotsNamespaces = kotsNamespaces
+
+	return info, nil
+}
+
+// timeoutError replaces err with a shorter one when it's because ctx ran out of time
+func timeoutError(ctx context.Context, err error, timeout time.Duration) error {
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func Test_check(t *testing.T) {
+	tests := []struct {
+		name       string
+		objects    []runtime.Object
+		wantHealth ClusterHealth
+		wantStatus string
+	}{
+		{
+			name: "all nodes ready",
+			objects: []runtime.Object{
+				node("a", corev1.ConditionTrue),
+				node("b", corev1.ConditionTrue),
+			},
+			wantHealth: ClusterHealth{
+				Reachable:     true,
+				ServerVersion: "v1.24.3",
+				Nodes:         2,
+				ReadyNodes:    2,
+			},
+			wantStatus: StatusReady,
+		},
+		{
+			name: "one node not ready",
+			objects: []runtime.Object{
+				node("a", corev1.ConditionTrue),
+				node("b", corev1.ConditionFalse),
+			},
+			wantHealth: ClusterHealth{
+				Reachable:     true,
+				ServerVersion: "v1.24.3",
+				Nodes:         2,
+				ReadyNodes:    1,
+			},
+			wantStatus: StatusNotReady,
+		},
+		{
+			name:    "no nodes",
+			objects: []runtime.Object{},
+			wantHealth: ClusterHealth{
+				Reachable:     true,
+				ServerVersion: "v1.24.3",
+			},
+			wantStatus: StatusNotReady,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"gitVersion": "v1.24.3"}`)
+			}))
+			defer server.Close()
+
+			clients := &cluster.Clients{
+				Kubernetes: fake.NewSimpleClientset(tt.objects...),
+				Discovery:  memory.NewMemCacheClient(discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL})),
+			}
+
+			h := &ClusterHealth{}
+			check(context.Background(), clients, h, Options{Timeout: time.Second})
+			req.Equal(tt.wantHealth, *h)
+			req.Equal(tt.wantStatus, h.Status())
+		})
+	}
+}
+
+func Test_checkTimeout(t *testing.T) {
+	req := require.New(t)
+
+	// an API server that never answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	clients := &cluster.Clients{
+		Kubernetes: fake.NewSimpleClientset(),
+		Discovery:  memory.NewMemCacheClient(discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL})),
+	}
+
+	h := &ClusterHealth{}
+	check(context.Background(), clients, h, Options{Timeout: 10 * time.Millisecond})
+	req.Equal("timed out after 10ms", h.Error)
+	req.Equal(StatusUnreachable, h.Status())
+}
+
+func node(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready},
+			},
+		},
+	}
+}
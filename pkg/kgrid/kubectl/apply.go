@@ -2,38 +2,98 @@ package kubectl
 
 import (
 	"bytes"
-	"io/ioutil"
-	"os"
-	"os/exec"
+	"context"
+	"encoding/json"
+	"io"
 
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
 )
 
-func Apply(c *types.ClusterConfig, yamlDoc string) error {
-	kubeconfigFile, err := ioutil.TempFile("", "kubectl")
+// FieldManager is the field manager name used for server-side apply
+const FieldManager = "kgrid"
+
+// Apply server-side applies every document in yamlDoc to the cluster
+func Apply(ctx context.Context, clients *cluster.Clients, yamlDoc string) error {
+	return apply(ctx, clients.Dynamic, clients.Discovery, yamlDoc)
+}
+
+func apply(ctx context.Context, dynamicClient dynamic.Interface, discoveryClient discovery.DiscoveryInterface, yamlDoc string) error {
+	objs, err := decodeObjects(yamlDoc)
 	if err != nil {
-		return errors.Wrap(err, "failed to create temp file")
+		return errors.Wrap(err, "failed to decode yaml")
 	}
-	defer os.RemoveAll(kubeconfigFile.Name())
 
-	if err := ioutil.WriteFile(kubeconfigFile.Name(), []byte(c.Kubeconfig), 0644); err != nil {
-		return errors.Wrap(err, "failed to create kubeconfig")
+	groupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to get api group resources")
 	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
 
-	args := []string{
-		"--kubeconfig", kubeconfigFile.Name(),
-		"apply",
-		"-f", "-",
-	}
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to map %s", gvk.String())
+		}
 
-	cmd := exec.Command("kubectl", args...)
-	cmd.Stdin = bytes.NewReader([]byte(yamlDoc))
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s %s", gvk.Kind, obj.GetName())
+		}
 
-	err = run(cmd)
-	if err != nil {
-		return errors.Wrap(err, "failed to run kubectl command")
+		var resource dynamic.ResourceInterface
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace := obj.GetNamespace()
+			if namespace == "" {
+				namespace = metav1.NamespaceDefault
+			}
+			resource = dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+		} else {
+			resource = dynamicClient.Resource(mapping.Resource)
+		}
+
+		force := true
+		_, err = resource.Patch(ctx, obj.GetName(), k8stypes.ApplyPatchType, data, metav1.PatchOptions{
+			FieldManager: FieldManager,
+			Force:        &force,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to apply %s %s", gvk.Kind, obj.GetName())
+		}
 	}
 
 	return nil
 }
+
+func decodeObjects(yamlDoc string) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(yamlDoc)), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		// skip empty documents
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
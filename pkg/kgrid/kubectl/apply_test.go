@@ -0,0 +1,131 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		yamlDoc  string
+		expected []k8stesting.PatchActionImpl
+		wantErr  string
+	}{
+		{
+			name: "namespaced and cluster scoped",
+			yamlDoc: `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: aws-auth
+  namespace: kube-system
+data:
+  a: b
+---
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: default
+provisioner: kubernetes.io/aws-ebs
+`,
+			expected: []k8stesting.PatchActionImpl{
+				patchAction("configmaps", "kube-system", "aws-auth"),
+				patchAction("storageclasses", "", "default"),
+			},
+		},
+		{
+			name: "defaults the namespace",
+			yamlDoc: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+`,
+			expected: []k8stesting.PatchActionImpl{
+				patchAction("configmaps", "default", "a"),
+			},
+		},
+		{
+			name: "unknown kind",
+			yamlDoc: `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: a
+`,
+			wantErr: "failed to map example.com/v1, Kind=Widget",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+			patches := []k8stesting.PatchActionImpl{}
+			dynamicClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patches = append(patches, action.(k8stesting.PatchActionImpl))
+				return true, nil, nil
+			})
+
+			err := apply(context.Background(), dynamicClient, fakeDiscovery(), test.yamlDoc)
+			if test.wantErr != "" {
+				req.Error(err)
+				assert.Contains(t, err.Error(), test.wantErr)
+				return
+			}
+			req.NoError(err)
+
+			req.Len(patches, len(test.expected))
+			for i, expected := range test.expected {
+				actual := patches[i]
+				assert.Equal(t, expected.Resource.Resource, actual.Resource.Resource)
+				assert.Equal(t, expected.Namespace, actual.Namespace)
+				assert.Equal(t, expected.Name, actual.Name)
+				assert.Equal(t, k8stypes.ApplyPatchType, actual.PatchType)
+
+				obj := map[string]interface{}{}
+				req.NoError(json.Unmarshal(actual.Patch, &obj))
+				assert.Equal(t, expected.Name, obj["metadata"].(map[string]interface{})["name"])
+			}
+		})
+	}
+}
+
+func patchAction(resource string, namespace string, name string) k8stesting.PatchActionImpl {
+	action := k8stesting.PatchActionImpl{Name: name}
+	action.Resource.Resource = resource
+	action.Namespace = namespace
+	return action
+}
+
+func fakeDiscovery() *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{
+		Fake: &k8stesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"},
+						{Name: "nodes", Namespaced: false, Kind: "Node"},
+					},
+				},
+				{
+					GroupVersion: "storage.k8s.io/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "storageclasses", Namespaced: false, Kind: "StorageClass"},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
+)
+
+// execConcurrency is the max number of clusters a command runs on at the same time
+const execConcurrency = 8
+
+// Binary is the kubectl binary run by the kubectl passthrough, found in PATH by default
+var Binary = "kubectl"
+
+// ExecResult is the outcome of running a command against one cluster
+type ExecResult struct {
+	ClusterName string
+	ExitCode    int
+	// Err is set when the command couldn't be run at all
+	Err error
+}
+
+// Exec runs command with KUBECONFIG set to the cluster's kubeconfig, and returns its exit code.
+// An error is only returned if the command couldn't be run.
+func Exec(ctx context.Context, clients *cluster.Clients, command string, args []string, stdout io.Writer, stderr io.Writer) (int, error) {
+	kubeconfigFile, removeKubeconfig, err := clients.KubeconfigFile()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to write kubeconfig")
+	}
+	defer removeKubeconfig()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("KUBECONFIG=%s", kubeconfigFile))
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	} else if err != nil {
+		return 0, errors.Wrapf(err, "failed to run %s", command)
+	}
+
+	return 0, nil
+}
+
+// ExecOnClusters runs the command against every cluster in parallel.
+// Each line of output is prefixed with the cluster name, and lines from different clusters aren't interleaved.
+func ExecOnClusters(ctx context.Context, gridName string, clusterConfigs []*types.ClusterConfig, command string, args []string, stdout io.Writer, stderr io.Writer) []ExecResult {
+	var mu sync.Mutex
+
+	tasks := []parallel.Task[int]{}
+	for _, c := range clusterConfigs {
+		c := c
+		tasks = append(tasks, func(ctx context.Context) (int, error) {
+			prefix := fmt.Sprintf("[%s] ", c.Name)
+			clusterStdout := newPrefixWriter(stdout, &mu, prefix)
+			clusterStderr := newPrefixWriter(stderr, &mu, prefix)
+			defer clusterStdout.Flush()
+			defer clusterStderr.Flush()
+
+			clients, err := cluster.GetClients(gridName, c)
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to get cluster clients")
+			}
+
+			return Exec(ctx, clients, command, args, clusterStdout, clusterStderr)
+		})
+	}
+
+	results := []ExecResult{}
+	for _, result := range parallel.Run(ctx, execConcurrency, tasks) {
+		results = append(results, ExecResult{
+			ClusterName: clusterConfigs[result.Index].Name,
+			ExitCode:    result.Value,
+			Err:         result.Err,
+		})
+	}
+
+	return results
+}
+
+// ExitCode combines the results into a single exit code: the highest exit code of any cluster,
+// or 1 if the command couldn't be run on a cluster and no cluster exited higher
+func ExitCode(results []ExecResult) int {
+	exitCode := 0
+	for _, result := range results {
+		code := result.ExitCode
+		if result.Err != nil && code == 0 {
+			code = 1
+		}
+		if code > exitCode {
+			exitCode = code
+		}
+	}
+
+	return exitCode
+}
+
+// prefixWriter writes whole lines to w, each starting with prefix.
+// Writers that share mu never interleave their lines.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *prefixWriter {
+	return &prefixWriter{
+		w:      w,
+		mu:     mu,
+		prefix: prefix,
+	}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush writes any partial last line, ending it with a newline
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := p.w.Write(append([]byte(p.prefix), line...))
+	return err
+}
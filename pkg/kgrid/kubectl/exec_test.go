@@ -0,0 +1,113 @@
+//go:build !windows
+
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://%s
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`
+
+func Test_ExecOnClusters(t *testing.T) {
+	req := require.New(t)
+
+	clusterConfigs := []*types.ClusterConfig{
+		{Name: "a", Kubeconfig: fmt.Sprintf(testKubeconfig, "a")},
+		{Name: "b", Kubeconfig: fmt.Sprintf(testKubeconfig, "b")},
+	}
+
+	// each cluster sees its own kubeconfig, and exits with a different code
+	script := `grep -o 'https://[a-z]*' "$KUBECONFIG"; printf partial; echo oops >&2; grep -q https://b "$KUBECONFIG" && exit 3; exit 0`
+
+	var stdout, stderr bytes.Buffer
+	results := ExecOnClusters(context.Background(), "grid", clusterConfigs, "sh", []string{"-c", script}, &stdout, &stderr)
+	req.Len(results, 2)
+
+	assert.Equal(t, ExecResult{ClusterName: "a", ExitCode: 0}, results[0])
+	assert.Equal(t, ExecResult{ClusterName: "b", ExitCode: 3}, results[1])
+	assert.Equal(t, 3, ExitCode(results))
+
+	assert.Equal(t, []string{"[a] https://a", "[a] partial", "[b] https://b", "[b] partial"}, sortedLines(stdout.String()))
+	assert.Equal(t, []string{"[a] oops", "[b] oops"}, sortedLines(stderr.String()))
+
+	results = ExecOnClusters(context.Background(), "grid", clusterConfigs[:1], "kgrid-test-command-that-does-not-exist", nil, &stdout, &stderr)
+	req.Len(results, 1)
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, 1, ExitCode(results))
+}
+
+func Test_prefixWriter(t *testing.T) {
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	w := newPrefixWriter(&buf, &mu, "[x] ")
+
+	fmt.Fprint(w, "one\ntw")
+	fmt.Fprint(w, "o\n\nthree")
+	assert.Equal(t, "[x] one\n[x] two\n[x] \n", buf.String())
+
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "[x] one\n[x] two\n[x] \n[x] three\n", buf.String())
+}
+
+func Test_ExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []ExecResult
+		expected int
+	}{
+		{
+			name:     "all succeeded",
+			results:  []ExecResult{{}, {}},
+			expected: 0,
+		},
+		{
+			name:     "highest exit code",
+			results:  []ExecResult{{ExitCode: 2}, {ExitCode: 1}},
+			expected: 2,
+		},
+		{
+			name:     "command not run",
+			results:  []ExecResult{{Err: errors.New("not found")}},
+			expected: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ExitCode(test.results))
+		})
+	}
+}
+
+func sortedLines(s string) []string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	sort.Strings(lines)
+	return lines
+}
@@ -1,43 +1,50 @@
 package kubectl
 
 import (
-	"io/ioutil"
-	"os"
-	"os/exec"
-	"strings"
+	"context"
+	"encoding/json"
 
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/rest"
 )
 
-func CheckAPIServer(c *types.ClusterConfig) error {
-	kubeconfigFile, err := ioutil.TempFile("", "kubectl")
-	if err != nil {
-		return errors.Wrap(err, "failed to create temp file")
-	}
-	defer os.RemoveAll(kubeconfigFile.Name())
+// CheckAPIServer returns nil if the cluster's API server is serving requests
+func CheckAPIServer(ctx context.Context, clients *cluster.Clients) error {
+	return checkAPIServer(ctx, clients.Discovery.RESTClient())
+}
 
-	if err := ioutil.WriteFile(kubeconfigFile.Name(), []byte(c.Kubeconfig), 0644); err != nil {
-		return errors.Wrap(err, "failed to create kubeconfig")
-	}
+func checkAPIServer(ctx context.Context, restClient rest.Interface) error {
+	_, err := serverVersion(ctx, restClient)
+	return err
+}
 
-	args := []string{
-		"--kubeconfig", kubeconfigFile.Name(),
-		"cluster-info",
-	}
+// ServerVersion returns the Kubernetes version of the cluster's API server
+func ServerVersion(ctx context.Context, clients *cluster.Clients) (string, error) {
+	return serverVersion(ctx, clients.Discovery.RESTClient())
+}
 
-	cmd := exec.Command("kubectl", args...)
+// serverVersion gets /version itself, since the discovery client's ServerVersion doesn't take a context
+// and would block on a dead API server until the connection times out
+func serverVersion(ctx context.Context, restClient rest.Interface) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 
-	stdout, stderr, err := runWithOutput(cmd)
+	body, err := restClient.Get().AbsPath("/version").Do(ctx).Raw()
 	if err != nil {
-		return errors.Wrapf(err, "failed to run kubectl command: %s", stderr)
+		return "", errors.Wrap(err, "failed to get server version")
+	}
+
+	info := version.Info{}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", errors.Wrap(err, "failed to parse server version")
 	}
 
-	// output contains terminal control characters in the middle of the phrase
-	if strings.Contains(string(stdout), "Kubernetes control plane") &&
-		strings.Contains(string(stdout), "is running") {
-		return nil
+	if info.GitVersion == "" {
+		return "", errors.New("server returned an empty version")
 	}
 
-	return errors.New(string(stderr))
+	return info.GitVersion, nil
 }
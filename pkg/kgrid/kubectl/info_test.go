@@ -0,0 +1,49 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+func Test_checkAPIServer(t *testing.T) {
+	req := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"gitVersion": "v1.24.3"}`)
+	}))
+	defer server.Close()
+	restClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}).RESTClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req.NoError(checkAPIServer(ctx, restClient))
+
+	cancel()
+	req.ErrorIs(checkAPIServer(ctx, restClient), context.Canceled)
+}
+
+func Test_serverVersionTimeout(t *testing.T) {
+	req := require.New(t)
+
+	// an API server that never answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	restClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}).RESTClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := serverVersion(ctx, restClient)
+	req.Error(err)
+	req.Less(time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,105 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "github.com/replicatedhq/kgrid/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/parallel"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type LogOptions struct {
+	// Follow keeps streaming until the container stops or ctx is cancelled
+	Follow bool
+	// Since only returns logs newer than this, if it's not zero
+	Since time.Duration
+}
+
+// LogStream is the log of one container
+type LogStream struct {
+	Clientset kubernetes.Interface
+	Namespace string
+	Pod       string
+	// Container can be empty if the pod only has one
+	Container string
+	// Prefix starts every line of the log
+	Prefix string
+}
+
+// PodLogStreams returns a stream for every container in the pods, each prefixed with prefix and the pod and container name
+func PodLogStreams(clientset kubernetes.Interface, pods []corev1.Pod, prefix string) []LogStream {
+	streams := []LogStream{}
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			streams = append(streams, LogStream{
+				Clientset: clientset,
+				Namespace: pod.Namespace,
+				Pod:       pod.Name,
+				Container: c.Name,
+				Prefix:    fmt.Sprintf("[%s%s/%s] ", prefix, pod.Name, c.Name),
+			})
+		}
+	}
+	return streams
+}
+
+// StreamLogs copies the logs of all streams to w at the same time.
+// Lines from different streams aren't interleaved.
+func StreamLogs(ctx context.Context, streams []LogStream, opts LogOptions, w io.Writer) error {
+	var mu sync.Mutex
+
+	tasks := []parallel.Task[struct{}]{}
+	for _, s := range streams {
+		s := s
+		tasks = append(tasks, func(ctx context.Context) (struct{}, error) {
+			pw := newPrefixWriter(w, &mu, s.Prefix)
+			defer pw.Flush()
+
+			return struct{}{}, streamLog(ctx, s, opts, pw)
+		})
+	}
+
+	// every stream runs at once, a followed log never finishes to make room for another
+	logErrors := []error{}
+	for _, result := range parallel.Run(ctx, len(tasks), tasks) {
+		if result.Err != nil {
+			s := streams[result.Index]
+			logErrors = append(logErrors, errors.Wrapf(result.Err, "logs for %s/%s", s.Pod, s.Container))
+		}
+	}
+	if len(logErrors) > 0 {
+		return &kerrors.MultiError{Errors: logErrors}
+	}
+
+	return nil
+}
+
+func streamLog(ctx context.Context, s LogStream, opts LogOptions, w io.Writer) error {
+	podLogOptions := &corev1.PodLogOptions{
+		Container: s.Container,
+		Follow:    opts.Follow,
+	}
+	if opts.Since > 0 {
+		sinceSeconds := int64(math.Ceil(opts.Since.Seconds()))
+		podLogOptions.SinceSeconds = &sinceSeconds
+	}
+
+	r, err := s.Clientset.CoreV1().Pods(s.Namespace).GetLogs(s.Pod, podLogOptions).Stream(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open log stream")
+	}
+	defer r.Close()
+
+	if _, err := io.Copy(w, r); err != nil && ctx.Err() == nil {
+		return errors.Wrap(err, "failed to read log stream")
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_StreamLogs(t *testing.T) {
+	req := require.New(t)
+
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "kotsadm-0", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "kotsadm"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "nginx"}, {Name: "sidecar"}},
+			},
+		},
+	}
+	clientset := fake.NewSimpleClientset()
+
+	streams := PodLogStreams(clientset, pods, "cluster ")
+	req.Len(streams, 3)
+
+	var out bytes.Buffer
+	err := StreamLogs(context.Background(), streams, LogOptions{}, &out)
+	req.NoError(err)
+
+	// the fake clientset returns the same log for every container
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	sort.Strings(lines)
+	req.Equal([]string{
+		"[cluster kotsadm-0/kotsadm] fake logs",
+		"[cluster web/nginx] fake logs",
+		"[cluster web/sidecar] fake logs",
+	}, lines)
+}
@@ -1,68 +1,35 @@
 package kubectl
 
 import (
-	"encoding/json"
-	"io/ioutil"
-	"os"
-	"os/exec"
+	"context"
 
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/cluster"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
-type Metadata struct {
-	Name string `json:"name"`
+func GetNodes(ctx context.Context, clients *cluster.Clients) (*corev1.NodeList, error) {
+	return getNodes(ctx, clients.Kubernetes)
 }
 
-type Conditions struct {
-	Message string `json:"message"`
-	Reason  string `json:"reason"`
-	Status  string `json:"status"`
-	Type    string `json:"type"`
-}
-
-type Node struct {
-	Metadata Metadata   `json:"metadata"`
-	Status   NodeStatus `json:"status"`
-}
-
-type NodeStatus struct {
-	Conditions []Conditions `json:"conditions"`
-}
-
-type Nodes struct {
-	Items []Node `json:"items"`
-}
-
-func GetNodes(c *types.ClusterConfig) (Nodes, error) {
-	kubeconfigFile, err := ioutil.TempFile("", "kubectl")
+func getNodes(ctx context.Context, clientset kubernetes.Interface) (*corev1.NodeList, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return Nodes{}, errors.Wrap(err, "failed to create temp file")
-	}
-	defer os.RemoveAll(kubeconfigFile.Name())
-
-	if err := ioutil.WriteFile(kubeconfigFile.Name(), []byte(c.Kubeconfig), 0644); err != nil {
-		return Nodes{}, errors.Wrap(err, "failed to create kubeconfig")
+		return nil, errors.Wrap(err, "failed to list nodes")
 	}
 
-	args := []string{
-		"--kubeconfig", kubeconfigFile.Name(),
-		"get", "nodes",
-		"-o", "json",
-	}
-
-	cmd := exec.Command("kubectl", args...)
-
-	stdout, stderr, err := runWithOutput(cmd)
-	if err != nil {
-		return Nodes{}, errors.Wrapf(err, "failed to run kubectl command: %s", stderr)
-	}
+	return nodes, nil
+}
 
-	nodes := Nodes{}
-	err = json.Unmarshal(stdout, &nodes)
-	if err != nil {
-		return Nodes{}, errors.Wrap(err, "failed to unmarshal nodes")
+// IsNodeReady returns true if the node's kubelet is reporting ready
+func IsNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			return true
+		}
 	}
 
-	return nodes, nil
+	return false
 }
@@ -0,0 +1,48 @@
+package kubectl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_getNodes(t *testing.T) {
+	req := require.New(t)
+
+	clientset := fake.NewSimpleClientset(
+		node("ready", corev1.ConditionTrue),
+		node("not-ready", corev1.ConditionFalse),
+	)
+
+	nodes, err := getNodes(context.Background(), clientset)
+	req.NoError(err)
+	req.Len(nodes.Items, 2)
+
+	ready := map[string]bool{}
+	for _, n := range nodes.Items {
+		ready[n.Name] = IsNodeReady(n)
+	}
+	assert.Equal(t, map[string]bool{"ready": true, "not-ready": false}, ready)
+}
+
+func node(name string, status corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:   corev1.NodeReady,
+					Status: status,
+					Reason: "KubeletReady",
+				},
+			},
+		},
+	}
+}
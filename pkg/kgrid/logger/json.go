@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	levelDebug = "debug"
+	levelInfo  = "info"
+	levelError = "error"
+)
+
+// jsonRecord is one line of JSONLogger output
+type jsonRecord struct {
+	Level     string `json:"level"`
+	Timestamp string `json:"ts"`
+	Message   string `json:"msg"`
+	Error     string `json:"error,omitempty"`
+	Fields
+}
+
+// JSONLogger writes one JSON object per line, for logs that are read by machines rather than people
+type JSONLogger struct {
+	// mu is shared with the loggers from WithFields so their lines don't interleave
+	mu     *sync.Mutex
+	w      io.Writer
+	fields Fields
+	now    func() time.Time
+
+	spinnerMsg      string
+	childSpinnerMsg string
+
+	isSilent  bool
+	isVerbose bool
+}
+
+func NewJSONLogger(w io.Writer, fields Fields) Logger {
+	return &JSONLogger{
+		mu:     &sync.Mutex{},
+		w:      w,
+		fields: fields,
+		now:    time.Now,
+	}
+}
+
+func (l *JSONLogger) Silence() {
+	if l == nil {
+		return
+	}
+	l.isSilent = true
+}
+
+func (l *JSONLogger) Verbose() {
+	if l == nil {
+		return
+	}
+	l.isVerbose = true
+}
+
+func (l *JSONLogger) WithFields(fields Fields) Logger {
+	return &JSONLogger{
+		mu:        l.mu,
+		w:         l.w,
+		fields:    l.fields.merge(fields),
+		now:       l.now,
+		isSilent:  l.isSilent,
+		isVerbose: l.isVerbose,
+	}
+}
+
+func (l *JSONLogger) ForCluster(name string) Logger {
+	return l.WithFields(Fields{Cluster: name})
+}
+
+func (l *JSONLogger) StartThread(msg string, args ...interface{}) {
+	l.write(levelInfo, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *JSONLogger) FinishThread(msg string, args ...interface{}) {
+	l.write(levelInfo, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *JSONLogger) Debug(msg string, args ...interface{}) {
+	if l == nil || !l.isVerbose {
+		return
+	}
+	l.write(levelDebug, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *JSONLogger) Info(msg string, args ...interface{}) {
+	l.write(levelInfo, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *JSONLogger) ActionWithoutSpinner(msg string, args ...interface{}) {
+	// the terminal logger uses an empty message for a blank line
+	if msg == "" {
+		return
+	}
+	l.write(levelInfo, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *JSONLogger) ChildActionWithoutSpinner(msg string, args ...interface{}) {
+	l.write(levelInfo, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *JSONLogger) ActionWithSpinner(msg string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.spinnerMsg = fmt.Sprintf(msg, args...)
+	l.write(levelInfo, l.spinnerMsg, nil)
+}
+
+func (l *JSONLogger) ChildActionWithSpinner(msg string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.childSpinnerMsg = fmt.Sprintf(msg, args...)
+	l.write(levelInfo, l.childSpinnerMsg, nil)
+}
+
+func (l *JSONLogger) FinishChildSpinner() {
+	if l == nil {
+		return
+	}
+	l.write(levelInfo, fmt.Sprintf("%s: done", l.childSpinnerMsg), nil)
+}
+
+func (l *JSONLogger) FinishSpinner() {
+	if l == nil {
+		return
+	}
+	l.write(levelInfo, fmt.Sprintf("%s: done", l.spinnerMsg), nil)
+}
+
+func (l *JSONLogger) FinishSpinnerWithError() {
+	if l == nil {
+		return
+	}
+	l.write(levelError, fmt.Sprintf("%s: failed", l.spinnerMsg), nil)
+}
+
+func (l *JSONLogger) Error(err error) {
+	l.write(levelError, "error", err)
+}
+
+func (l *JSONLogger) TestResult(app string, err error) {
+	if err != nil {
+		l.write(levelError, fmt.Sprintf("test of %s failed", app), err)
+		return
+	}
+	l.write(levelInfo, fmt.Sprintf("test of %s passed", app), nil)
+}
+
+func (l *JSONLogger) Attach(title string, path string) {
+	l.write(levelInfo, fmt.Sprintf("%s: %s", title, path), nil)
+}
+
+func (l *JSONLogger) write(level string, msg string, err error) {
+	if l == nil || l.isSilent {
+		return
+	}
+
+	record := jsonRecord{
+		Level:     level,
+		Timestamp: l.now().UTC().Format(time.RFC3339Nano),
+		Message:   msg,
+		Fields:    l.fields,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	b, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		log.Println("failed to marshal log record", marshalErr)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(append(b, '\n'))
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JSONLogger(t *testing.T) {
+	req := require.New(t)
+
+	var out bytes.Buffer
+	l := NewJSONLogger(&out, Fields{Grid: "grid", Cluster: "cluster", TestID: "abc"}).(*JSONLogger)
+	l.now = func() time.Time { return time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC) }
+
+	l.Debug("not verbose")
+	l.ActionWithSpinner("Creating cluster %s", "cluster")
+	l.FinishSpinnerWithError()
+	l.Error(errors.New("boom"))
+	l.Verbose()
+	l.Debug("verbose")
+
+	req.Equal(`{"level":"info","ts":"2022-08-01T12:00:00Z","msg":"Creating cluster cluster","grid":"grid","cluster":"cluster","testId":"abc"}
+{"level":"error","ts":"2022-08-01T12:00:00Z","msg":"Creating cluster cluster: failed","grid":"grid","cluster":"cluster","testId":"abc"}
+{"level":"error","ts":"2022-08-01T12:00:00Z","msg":"error","error":"boom","grid":"grid","cluster":"cluster","testId":"abc"}
+{"level":"debug","ts":"2022-08-01T12:00:00Z","msg":"verbose","grid":"grid","cluster":"cluster","testId":"abc"}
+`, out.String())
+}
+
+func Test_JSONLogger_ForCluster(t *testing.T) {
+	req := require.New(t)
+
+	var out bytes.Buffer
+	l := NewJSONLogger(&out, Fields{Grid: "grid", TestID: "abc"}).(*JSONLogger)
+	l.now = func() time.Time { return time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC) }
+
+	l.ForCluster("cluster-a").Info("Creating VPC")
+	l.Info("done")
+
+	req.Equal(`{"level":"info","ts":"2022-08-01T12:00:00Z","msg":"Creating VPC","grid":"grid","cluster":"cluster-a","testId":"abc"}
+{"level":"info","ts":"2022-08-01T12:00:00Z","msg":"done","grid":"grid","testId":"abc"}
+`, out.String())
+}
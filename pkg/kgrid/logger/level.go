@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"github.com/pkg/errors"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelError
+)
+
+// ParseLevel parses debug, info, or error. An empty level is info.
+func ParseLevel(level string) (Level, error) {
+	switch level {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, errors.Errorf("unknown log level %q, must be one of debug, info, or error", level)
+}
+
+// levelLogger drops everything less severe than its level before it reaches the sink.
+// Threads are always passed on, since they hold the messages that are, and so are test results.
+// Spinners are info, even when they fail, so a finish is never sent without its start.
+type levelLogger struct {
+	sink  Logger
+	level Level
+}
+
+// NewLevelLogger wraps sink so it only gets messages at level or above
+func NewLevelLogger(sink Logger, level Level) Logger {
+	if level == LevelDebug {
+		sink.Verbose()
+	}
+	return &levelLogger{
+		sink:  sink,
+		level: level,
+	}
+}
+
+func (l *levelLogger) enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *levelLogger) Silence() {
+	l.sink.Silence()
+}
+
+// Verbose doesn't change the level, the sink's level is what was asked for
+func (l *levelLogger) Verbose() {
+	l.sink.Verbose()
+}
+
+func (l *levelLogger) WithFields(fields Fields) Logger {
+	return &levelLogger{
+		sink:  l.sink.WithFields(fields),
+		level: l.level,
+	}
+}
+
+func (l *levelLogger) ForCluster(name string) Logger {
+	return l.WithFields(Fields{Cluster: name})
+}
+
+func (l *levelLogger) StartThread(msg string, args ...interface{}) {
+	l.sink.StartThread(msg, args...)
+}
+
+func (l *levelLogger) FinishThread(msg string, args ...interface{}) {
+	l.sink.FinishThread(msg, args...)
+}
+
+func (l *levelLogger) TestResult(app string, err error) {
+	l.sink.TestResult(app, err)
+}
+
+func (l *levelLogger) Attach(title string, path string) {
+	if l.enabled(LevelInfo) {
+		l.sink.Attach(title, path)
+	}
+}
+
+func (l *levelLogger) Debug(msg string, args ...interface{}) {
+	if l.enabled(LevelDebug) {
+		l.sink.Debug(msg, args...)
+	}
+}
+
+func (l *levelLogger) Info(msg string, args ...interface{}) {
+	if l.enabled(LevelInfo) {
+		l.sink.Info(msg, args...)
+	}
+}
+
+func (l *levelLogger) ActionWithoutSpinner(msg string, args ...interface{}) {
+	if l.enabled(LevelInfo) {
+		l.sink.ActionWithoutSpinner(msg, args...)
+	}
+}
+
+func (l *levelLogger) ChildActionWithoutSpinner(msg string, args ...interface{}) {
+	if l.enabled(LevelInfo) {
+		l.sink.ChildActionWithoutSpinner(msg, args...)
+	}
+}
+
+func (l *levelLogger) ActionWithSpinner(msg string, args ...interface{}) {
+	if l.enabled(LevelInfo) {
+		l.sink.ActionWithSpinner(msg, args...)
+	}
+}
+
+func (l *levelLogger) ChildActionWithSpinner(msg string, args ...interface{}) {
+	if l.enabled(LevelInfo) {
+		l.sink.ChildActionWithSpinner(msg, args...)
+	}
+}
+
+func (l *levelLogger) FinishChildSpinner() {
+	if l.enabled(LevelInfo) {
+		l.sink.FinishChildSpinner()
+	}
+}
+
+func (l *levelLogger) FinishSpinner() {
+	if l.enabled(LevelInfo) {
+		l.sink.FinishSpinner()
+	}
+}
+
+func (l *levelLogger) FinishSpinnerWithError() {
+	if l.enabled(LevelInfo) {
+		l.sink.FinishSpinnerWithError()
+	}
+}
+
+func (l *levelLogger) Error(err error) {
+	if l.enabled(LevelError) {
+		l.sink.Error(err)
+	}
+}
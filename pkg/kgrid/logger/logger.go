@@ -1,12 +1,27 @@
 package logger
 
 import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 )
 
+const (
+	// FormatAuto uses json when stdout isn't a terminal, like in a test pod, and terminal otherwise
+	FormatAuto     = "auto"
+	FormatTerminal = "terminal"
+	FormatJSON     = "json"
+)
+
 type Logger interface {
 	Silence()
 	Verbose()
+	// WithFields returns a logger that tags everything it logs with fields, on top of this logger's own
+	WithFields(fields Fields) Logger
+	// ForCluster is WithFields for a single cluster, so messages from clusters handled in parallel can be told apart
+	ForCluster(name string) Logger
 	StartThread(msg string, args ...interface{})
 	FinishThread(msg string, args ...interface{})
 	Debug(msg string, args ...interface{})
@@ -19,11 +34,139 @@ type Logger interface {
 	FinishSpinner()
 	FinishSpinnerWithError()
 	Error(err error)
+	// TestResult reports whether testing app passed, err is nil if it did
+	TestResult(app string, err error)
+	// Attach shares the file at path, like a support bundle. Sinks that can't upload it log the path.
+	Attach(title string, path string)
+}
+
+// Fields identify what a log line is about. Empty fields are left out.
+type Fields struct {
+	Grid    string `json:"grid,omitempty"`
+	Cluster string `json:"cluster,omitempty"`
+	TestID  string `json:"testId,omitempty"`
+}
+
+// merge returns f with the non-empty fields of other set over it
+func (f Fields) merge(other Fields) Fields {
+	if other.Grid != "" {
+		f.Grid = other.Grid
+	}
+	if other.Cluster != "" {
+		f.Cluster = other.Cluster
+	}
+	if other.TestID != "" {
+		f.TestID = other.TestID
+	}
+	return f
+}
+
+// Options choose how logs are written when they're not sent to slack
+type Options struct {
+	Format string
+	// Fields are added to every json log line
+	Fields Fields
+}
+
+// NewLogger sends logs to every sink in the spec. Without sinks, logs go to the console in opts,
+// and to Slack as well if the spec has it.
+func NewLogger(loggerSpec types.LoggerSpec, opts Options) (Logger, error) {
+	if err := ValidateFormat(opts.Format); err != nil {
+		return nil, err
+	}
+
+	sinkSpecs := loggerSpec.Sinks
+	if len(sinkSpecs) == 0 {
+		sinkSpecs = []types.LoggerSinkSpec{{Console: &types.ConsoleLoggerSpec{}}}
+		if loggerSpec.Slack != nil {
+			sinkSpecs = append(sinkSpecs, types.LoggerSinkSpec{Slack: loggerSpec.Slack})
+		}
+	}
+
+	sinks := []Logger{}
+	for i, sinkSpec := range sinkSpecs {
+		sink, err := newSink(sinkSpec, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "logger sink %d", i)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return NewMultiLogger(sinks...), nil
+}
+
+func newSink(sinkSpec types.LoggerSinkSpec, opts Options) (Logger, error) {
+	level, err := ParseLevel(sinkSpec.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	set := 0
+	for _, isSet := range []bool{sinkSpec.Console != nil, sinkSpec.Slack != nil, sinkSpec.Webhook != nil, sinkSpec.Teams != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errors.New("only one of console, slack, webhook, and teams can be set")
+	}
+
+	var sink Logger
+	switch {
+	case sinkSpec.Console != nil:
+		consoleOpts := opts
+		if sinkSpec.Console.Format != "" {
+			if err := ValidateFormat(sinkSpec.Console.Format); err != nil {
+				return nil, err
+			}
+			consoleOpts.Format = sinkSpec.Console.Format
+		}
+		sink = NewConsoleLogger(consoleOpts)
+	case sinkSpec.Slack != nil:
+		sink = NewSlackLogger(sinkSpec.Slack)
+	case sinkSpec.Webhook != nil:
+		sink, err = NewWebhookLogger(sinkSpec.Webhook)
+	case sinkSpec.Teams != nil:
+		sink, err = NewTeamsLogger(sinkSpec.Teams)
+	default:
+		return nil, errors.New("console, slack, webhook, or teams is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLevelLogger(sink, level), nil
 }
 
-func NewLogger(loggerSpec types.LoggerSpec) Logger {
-	if loggerSpec.Slack != nil {
-		return NewSlackLogger(loggerSpec.Slack)
+// NewConsoleLogger writes to stdout in the format in opts
+func NewConsoleLogger(opts Options) Logger {
+	format := opts.Format
+	if format == "" || format == FormatAuto {
+		format = FormatTerminal
+		if !isatty.IsTerminal(os.Stdout.Fd()) {
+			format = FormatJSON
+		}
+	}
+
+	if format == FormatJSON {
+		fields := opts.Fields
+		// set by the operator in test pods
+		if fields.TestID == "" {
+			fields.TestID = os.Getenv("TEST_ID")
+		}
+		return NewJSONLogger(os.Stdout, fields)
 	}
 	return NewTerminalLogger()
 }
+
+// ValidateFormat returns an error if format isn't a log format
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatAuto, FormatTerminal, FormatJSON:
+		return nil
+	}
+	return errors.Errorf("unknown log format %q, must be one of auto, terminal, or json", format)
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MultiLoggerLevels(t *testing.T) {
+	req := require.New(t)
+
+	now := func() time.Time { return time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC) }
+	var debugOut, errorOut bytes.Buffer
+	debugSink := NewJSONLogger(&debugOut, Fields{}).(*JSONLogger)
+	debugSink.now = now
+	errorSink := NewJSONLogger(&errorOut, Fields{}).(*JSONLogger)
+	errorSink.now = now
+
+	l := NewMultiLogger(NewLevelLogger(debugSink, LevelDebug), NewLevelLogger(errorSink, LevelError))
+	l.Debug("debug")
+	l.Info("info")
+	l.Error(errors.New("boom"))
+
+	req.Equal(`{"level":"debug","ts":"2022-08-01T12:00:00Z","msg":"debug"}
+{"level":"info","ts":"2022-08-01T12:00:00Z","msg":"info"}
+{"level":"error","ts":"2022-08-01T12:00:00Z","msg":"error","error":"boom"}
+`, debugOut.String())
+	req.Equal(`{"level":"error","ts":"2022-08-01T12:00:00Z","msg":"error","error":"boom"}
+`, errorOut.String())
+}
+
+func Test_NewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    types.LoggerSpec
+		opts    Options
+		wantErr string
+	}{
+		{
+			name: "default console",
+			spec: types.LoggerSpec{},
+			opts: Options{Format: FormatJSON},
+		},
+		{
+			name: "sinks",
+			spec: types.LoggerSpec{
+				Sinks: []types.LoggerSinkSpec{
+					{Level: "debug", Console: &types.ConsoleLoggerSpec{Format: FormatTerminal}},
+					{Level: "error", Slack: &types.SlackLoggerSpec{Channel: "tests"}},
+				},
+			},
+		},
+		{
+			name:    "unknown format",
+			opts:    Options{Format: "xml"},
+			wantErr: `unknown log format "xml", must be one of auto, terminal, or json`,
+		},
+		{
+			name: "unknown level",
+			spec: types.LoggerSpec{
+				Sinks: []types.LoggerSinkSpec{
+					{Level: "warn", Console: &types.ConsoleLoggerSpec{}},
+				},
+			},
+			wantErr: `logger sink 0: unknown log level "warn", must be one of debug, info, or error`,
+		},
+		{
+			name: "empty sink",
+			spec: types.LoggerSpec{
+				Sinks: []types.LoggerSinkSpec{
+					{Console: &types.ConsoleLoggerSpec{}},
+					{Level: "info"},
+				},
+			},
+			wantErr: "logger sink 1: console, slack, webhook, or teams is required",
+		},
+		{
+			name: "two destinations",
+			spec: types.LoggerSpec{
+				Sinks: []types.LoggerSinkSpec{
+					{
+						Console: &types.ConsoleLoggerSpec{},
+						Webhook: &types.WebhookLoggerSpec{URL: types.ValueOrValueFrom{Value: "http://localhost"}},
+					},
+				},
+			},
+			wantErr: "logger sink 0: only one of console, slack, webhook, and teams can be set",
+		},
+		{
+			name: "webhook without url",
+			spec: types.LoggerSpec{
+				Sinks: []types.LoggerSinkSpec{
+					{Webhook: &types.WebhookLoggerSpec{}},
+				},
+			},
+			wantErr: "logger sink 0: failed to get webhook url: unable to find supported value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			l, err := NewLogger(tt.spec, tt.opts)
+			if tt.wantErr != "" {
+				req.EqualError(err, tt.wantErr)
+				return
+			}
+			req.NoError(err)
+			req.NotNil(l)
+		})
+	}
+}
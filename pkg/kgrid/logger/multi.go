@@ -0,0 +1,120 @@
+package logger
+
+// MultiLogger sends everything it's given to each of its sinks
+type MultiLogger struct {
+	sinks []Logger
+}
+
+func NewMultiLogger(sinks ...Logger) Logger {
+	return &MultiLogger{
+		sinks: sinks,
+	}
+}
+
+func (l *MultiLogger) Silence() {
+	for _, s := range l.sinks {
+		s.Silence()
+	}
+}
+
+func (l *MultiLogger) Verbose() {
+	for _, s := range l.sinks {
+		s.Verbose()
+	}
+}
+
+func (l *MultiLogger) WithFields(fields Fields) Logger {
+	sinks := make([]Logger, 0, len(l.sinks))
+	for _, s := range l.sinks {
+		sinks = append(sinks, s.WithFields(fields))
+	}
+	return NewMultiLogger(sinks...)
+}
+
+func (l *MultiLogger) ForCluster(name string) Logger {
+	return l.WithFields(Fields{Cluster: name})
+}
+
+func (l *MultiLogger) StartThread(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.StartThread(msg, args...)
+	}
+}
+
+func (l *MultiLogger) FinishThread(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.FinishThread(msg, args...)
+	}
+}
+
+func (l *MultiLogger) Debug(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.Debug(msg, args...)
+	}
+}
+
+func (l *MultiLogger) Info(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.Info(msg, args...)
+	}
+}
+
+func (l *MultiLogger) ActionWithoutSpinner(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.ActionWithoutSpinner(msg, args...)
+	}
+}
+
+func (l *MultiLogger) ChildActionWithoutSpinner(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.ChildActionWithoutSpinner(msg, args...)
+	}
+}
+
+func (l *MultiLogger) ActionWithSpinner(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.ActionWithSpinner(msg, args...)
+	}
+}
+
+func (l *MultiLogger) ChildActionWithSpinner(msg string, args ...interface{}) {
+	for _, s := range l.sinks {
+		s.ChildActionWithSpinner(msg, args...)
+	}
+}
+
+func (l *MultiLogger) FinishChildSpinner() {
+	for _, s := range l.sinks {
+		s.FinishChildSpinner()
+	}
+}
+
+func (l *MultiLogger) FinishSpinner() {
+	for _, s := range l.sinks {
+		s.FinishSpinner()
+	}
+}
+
+func (l *MultiLogger) FinishSpinnerWithError() {
+	for _, s := range l.sinks {
+		s.FinishSpinnerWithError()
+	}
+}
+
+func (l *MultiLogger) Error(err error) {
+	for _, s := range l.sinks {
+		s.Error(err)
+	}
+}
+
+func (l *MultiLogger) Attach(title string, path string) {
+	for _, s := range l.sinks {
+		s.Attach(title, path)
+	}
+}
+
+func (l *MultiLogger) TestResult(app string, err error) {
+	for _, s := range l.sinks {
+		s.TestResult(app, err)
+	}
+}
@@ -3,13 +3,30 @@ package logger
 import (
 	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
-	"github.com/fatih/color"
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
 	"github.com/slack-go/slack"
 )
 
+const (
+	// slackMaxRetries is how many times a rate limited request is retried before it's dropped
+	slackMaxRetries = 5
+	// slackMaxTextLength and slackMaxFieldLength are the most text slack accepts in a section and in one of its fields
+	slackMaxTextLength  = 3000
+	slackMaxFieldLength = 2000
+	// slackMaxFields is the most fields slack accepts in a section
+	slackMaxFields = 10
+)
+
+// slackSleep waits out rate limits, tests replace it
+var slackSleep = time.Sleep
+
 type SlackLogger struct {
 	// slack auth
 	token   string
@@ -22,16 +39,20 @@ type SlackLogger struct {
 	initialMessage string
 	threadDoneCh   chan struct{}
 
+	// clusters that logged into the thread, for the summary posted when it finishes
+	mu       sync.Mutex
+	clusters []*slackClusterStatus
+
 	// general logger stuff
-	isSilent    bool
-	isVerbose   bool
+	isSilent  bool
+	isVerbose bool
+	// printToLogs is set when slack can't be reached, the console sinks print everything else
 	printToLogs bool
 }
 
+// NewSlackLogger posts threads, info, and errors to a slack channel
 func NewSlackLogger(loggerSpec *types.SlackLoggerSpec) Logger {
-	l := &SlackLogger{
-		printToLogs: true,
-	}
+	l := &SlackLogger{}
 
 	token, err := loggerSpec.Token.String()
 	if err != nil {
@@ -45,6 +66,8 @@ func NewSlackLogger(loggerSpec *types.SlackLoggerSpec) Logger {
 
 	if !l.isSilent && l.token != "" {
 		l.client = slack.New(l.token)
+	} else {
+		l.printToLogs = true
 	}
 
 	return l
@@ -64,21 +87,122 @@ func (l *SlackLogger) Verbose() {
 	l.isVerbose = true
 }
 
+// WithFields returns a logger that posts into this logger's thread with the cluster name in front of each message.
+// Slack threads can't be nested, so other fields are left out.
+func (l *SlackLogger) WithFields(fields Fields) Logger {
+	if fields.Cluster == "" {
+		return l
+	}
+	return &slackClusterLogger{
+		parent: l,
+		status: l.clusterStatus(fields.Cluster),
+	}
+}
+
+func (l *SlackLogger) ForCluster(name string) Logger {
+	return l.WithFields(Fields{Cluster: name})
+}
+
+func (l *SlackLogger) clusterStatus(name string) *slackClusterStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, status := range l.clusters {
+		if status.name == name {
+			return status
+		}
+	}
+	status := &slackClusterStatus{name: name}
+	l.clusters = append(l.clusters, status)
+	return status
+}
+
+// summary lists each cluster in the thread and whether it failed, it's the text for summaryBlocks
+func (l *SlackLogger) summary() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.clusters) == 0 {
+		return ""
+	}
+
+	lines := []string{"Clusters:"}
+	for _, status := range l.clusters {
+		lines = append(lines, status.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// summaryBlocks is a table of each cluster in the thread and its result
+func (l *SlackLogger) summaryBlocks() []slack.Block {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.clusters) == 0 {
+		return nil
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(nil, []*slack.TextBlockObject{markdown("*Cluster*"), markdown("*Result*")}, nil),
+	}
+	fields := []*slack.TextBlockObject{}
+	for _, status := range l.clusters {
+		fields = append(fields, markdown(status.name), markdown(truncate(status.result(), slackMaxFieldLength)))
+		if len(fields) == slackMaxFields {
+			blocks = append(blocks, slack.NewSectionBlock(nil, fields, nil))
+			fields = []*slack.TextBlockObject{}
+		}
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(nil, fields, nil))
+	}
+	return blocks
+}
+
+// postToThread sends a reply to the thread, with text as the fallback for clients that don't show blocks
+func (l *SlackLogger) postToThread(text string, blocks ...slack.Block) error {
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionTS(l.threadTS),
+		slack.MsgOptionAsUser(true),
+	}
+	if len(blocks) > 0 {
+		opts = append(opts, slack.MsgOptionBlocks(blocks...))
+	}
+
+	return retry(func() error {
+		_, _, err := l.client.PostMessage(l.channel, opts...)
+		return err
+	})
+}
+
 func (l *SlackLogger) StartThread(msg string, args ...interface{}) {
 	if l == nil || l.isSilent {
 		return
 	}
 
+	l.mu.Lock()
+	l.clusters = nil
+	l.mu.Unlock()
+
+	l.initialMessage = fmt.Sprintf(msg, args...)
 	if l.printToLogs {
-		log.Printf(l.initialMessage)
+		log.Print(l.initialMessage)
+	}
+	if l.client == nil {
+		return
 	}
 
-	l.initialMessage = fmt.Sprintf(msg, args...)
-	channelID, timestamp, err := l.client.PostMessage(
-		l.channel,
-		slack.MsgOptionText(l.initialMessage, false),
-		slack.MsgOptionAsUser(true),
-	)
+	var channelID, timestamp string
+	err := retry(func() error {
+		var err error
+		channelID, timestamp, err = l.client.PostMessage(
+			l.channel,
+			slack.MsgOptionText(l.initialMessage, false),
+			slack.MsgOptionAsUser(true),
+		)
+		return err
+	})
 	if err != nil {
 		log.Println("failed to send slack message", err)
 		l.printToLogs = true
@@ -97,8 +221,12 @@ func (l *SlackLogger) FinishThread(msg string, args ...interface{}) {
 		return
 	}
 
+	summary := l.summary()
 	if l.printToLogs {
 		log.Printf(msg, args...)
+		if summary != "" {
+			log.Print(summary)
+		}
 	}
 
 	// this could be nil if StartThread failed before creating channel
@@ -108,12 +236,21 @@ func (l *SlackLogger) FinishThread(msg string, args ...interface{}) {
 
 	close(l.threadDoneCh)
 
-	_, _, _, err := l.client.UpdateMessage(
-		l.channelID,
-		l.threadTS,
-		slack.MsgOptionText(fmt.Sprintf(msg, args...), false),
-		slack.MsgOptionAsUser(true),
-	)
+	if summary != "" {
+		if err := l.postToThread(summary, l.summaryBlocks()...); err != nil {
+			log.Println("failed to send slack summary message", err)
+		}
+	}
+
+	err := retry(func() error {
+		_, _, _, err := l.client.UpdateMessage(
+			l.channelID,
+			l.threadTS,
+			slack.MsgOptionText(fmt.Sprintf(msg, args...), false),
+			slack.MsgOptionAsUser(true),
+		)
+		return err
+	})
 	if err != nil {
 		log.Println("failed to update main message", err)
 	}
@@ -124,9 +261,9 @@ func (l *SlackLogger) Debug(msg string, args ...interface{}) {
 		return
 	}
 
-	fmt.Printf("    ")
-	fmt.Println(fmt.Sprintf(msg, args...))
-	fmt.Println("")
+	if l.printToLogs {
+		log.Printf(msg, args...)
+	}
 }
 
 func (l *SlackLogger) Info(msg string, args ...interface{}) {
@@ -137,30 +274,25 @@ func (l *SlackLogger) Info(msg string, args ...interface{}) {
 	if l.printToLogs {
 		log.Printf(msg, args...)
 	}
+	if l.client == nil {
+		return
+	}
 
-	_, _, err := l.client.PostMessage(
-		l.channel,
-		slack.MsgOptionText(fmt.Sprintf(msg, args...), false), // TODO: needs app slug, release sequense
-		slack.MsgOptionTS(l.threadTS),
-		slack.MsgOptionAsUser(true),
-	)
-	if err != nil {
+	// TODO: needs app slug, release sequense
+	text := fmt.Sprintf(msg, args...)
+	if err := l.postToThread(text, textSection(text)); err != nil {
 		log.Println("failed to send slack info message", err)
 	}
 }
 
 func (l *SlackLogger) ActionWithoutSpinner(msg string, args ...interface{}) {
-	if l == nil || l.isSilent {
+	if l == nil || l.isSilent || msg == "" {
 		return
 	}
 
-	if msg == "" {
-		fmt.Println("")
-		return
+	if l.printToLogs {
+		log.Printf(msg, args...)
 	}
-
-	fmt.Printf("  • ")
-	fmt.Println(fmt.Sprintf(msg, args...))
 }
 
 func (l *SlackLogger) ChildActionWithoutSpinner(msg string, args ...interface{}) {
@@ -168,8 +300,9 @@ func (l *SlackLogger) ChildActionWithoutSpinner(msg string, args ...interface{})
 		return
 	}
 
-	fmt.Printf("    • ")
-	fmt.Println(fmt.Sprintf(msg, args...))
+	if l.printToLogs {
+		log.Printf(msg, args...)
+	}
 }
 
 func (l *SlackLogger) ActionWithSpinner(msg string, args ...interface{}) {
@@ -300,9 +433,49 @@ func (l *SlackLogger) Error(err error) {
 		return
 	}
 
-	c := color.New(color.FgHiRed)
-	c.Printf("  • ")
-	c.Println(fmt.Sprintf("%#v", err))
+	if l.printToLogs {
+		log.Println(err)
+	}
+	if l.client == nil {
+		return
+	}
+
+	text := fmt.Sprintf(":x: %s", err.Error())
+	if postErr := l.postToThread(text, textSection(text)); postErr != nil {
+		log.Println("failed to send slack error message", postErr)
+	}
+}
+
+// TestResult doesn't post anything, the thread's finish message already shows the result
+func (l *SlackLogger) TestResult(app string, err error) {
+}
+
+// Attach uploads the file at path into the thread
+func (l *SlackLogger) Attach(title string, path string) {
+	if l == nil || l.isSilent {
+		return
+	}
+
+	if l.printToLogs {
+		log.Printf("%s: %s", title, path)
+	}
+	if l.client == nil {
+		return
+	}
+
+	err := retry(func() error {
+		_, err := l.client.UploadFile(slack.FileUploadParameters{
+			File:            path,
+			Filename:        filepath.Base(path),
+			Title:           title,
+			Channels:        []string{l.channel},
+			ThreadTimestamp: l.threadTS,
+		})
+		return err
+	})
+	if err != nil {
+		log.Println("failed to upload file to slack", err)
+	}
 }
 
 func (l *SlackLogger) monitorThread() {
@@ -323,8 +496,11 @@ func (l *SlackLogger) monitorThread() {
 			)
 			spinnerIdx = (spinnerIdx + 1) % len(spinners)
 
+			var rateLimited *slack.RateLimitedError
 			if err == nil {
 				delayPeriod = 5 * time.Second
+			} else if errors.As(err, &rateLimited) {
+				delayPeriod = rateLimited.RetryAfter
 			} else {
 				log.Println("failed to update spinner", err)
 				delayPeriod = 15 * time.Second
@@ -332,3 +508,166 @@ func (l *SlackLogger) monitorThread() {
 		}
 	}
 }
+
+type slackClusterStatus struct {
+	name   string
+	failed bool
+	err    error
+}
+
+// result is the cluster's cell in the summary table
+func (s *slackClusterStatus) result() string {
+	if !s.failed {
+		return ":white_check_mark: ok"
+	}
+	if s.err == nil {
+		return ":x: failed"
+	}
+	return fmt.Sprintf(":x: %s", s.err.Error())
+}
+
+func (s *slackClusterStatus) String() string {
+	if !s.failed {
+		return fmt.Sprintf(":white_check_mark: %s", s.name)
+	}
+	if s.err == nil {
+		return fmt.Sprintf(":x: %s", s.name)
+	}
+	return fmt.Sprintf(":x: %s: %s", s.name, s.err.Error())
+}
+
+// slackClusterLogger posts into its parent's thread for one cluster, and records whether the cluster failed
+type slackClusterLogger struct {
+	parent *SlackLogger
+	status *slackClusterStatus
+}
+
+// Silence and Verbose apply to the whole thread
+func (l *slackClusterLogger) Silence() {
+	l.parent.Silence()
+}
+
+func (l *slackClusterLogger) Verbose() {
+	l.parent.Verbose()
+}
+
+func (l *slackClusterLogger) WithFields(fields Fields) Logger {
+	if fields.Cluster == "" || fields.Cluster == l.status.name {
+		return l
+	}
+	return l.parent.WithFields(fields)
+}
+
+func (l *slackClusterLogger) ForCluster(name string) Logger {
+	return l.WithFields(Fields{Cluster: name})
+}
+
+// StartThread and FinishThread post into the parent's thread, since a cluster doesn't get a thread of its own
+func (l *slackClusterLogger) StartThread(msg string, args ...interface{}) {
+	l.parent.Info("%s", l.format(msg, args...))
+}
+
+func (l *slackClusterLogger) FinishThread(msg string, args ...interface{}) {
+	l.parent.Info("%s", l.format(msg, args...))
+}
+
+func (l *slackClusterLogger) Debug(msg string, args ...interface{}) {
+	l.parent.Debug("%s", l.format(msg, args...))
+}
+
+func (l *slackClusterLogger) Info(msg string, args ...interface{}) {
+	l.parent.Info("%s", l.format(msg, args...))
+}
+
+func (l *slackClusterLogger) ActionWithoutSpinner(msg string, args ...interface{}) {
+	if msg == "" {
+		return
+	}
+	l.parent.ActionWithoutSpinner("%s", l.format(msg, args...))
+}
+
+func (l *slackClusterLogger) ChildActionWithoutSpinner(msg string, args ...interface{}) {
+	l.parent.ChildActionWithoutSpinner("%s", l.format(msg, args...))
+}
+
+func (l *slackClusterLogger) ActionWithSpinner(msg string, args ...interface{}) {
+}
+
+func (l *slackClusterLogger) ChildActionWithSpinner(msg string, args ...interface{}) {
+}
+
+func (l *slackClusterLogger) FinishChildSpinner() {
+}
+
+func (l *slackClusterLogger) FinishSpinner() {
+}
+
+func (l *slackClusterLogger) FinishSpinnerWithError() {
+	l.fail(nil)
+}
+
+func (l *slackClusterLogger) Error(err error) {
+	l.fail(err)
+	l.parent.Error(errors.Wrapf(err, "*%s*", l.status.name))
+}
+
+func (l *slackClusterLogger) TestResult(app string, err error) {
+	l.parent.TestResult(app, err)
+}
+
+func (l *slackClusterLogger) Attach(title string, path string) {
+	l.parent.Attach(l.format("%s", title), path)
+}
+
+func (l *slackClusterLogger) fail(err error) {
+	l.parent.mu.Lock()
+	defer l.parent.mu.Unlock()
+
+	l.status.failed = true
+	if err != nil {
+		l.status.err = err
+	}
+}
+
+func (l *slackClusterLogger) format(msg string, args ...interface{}) string {
+	return fmt.Sprintf("*%s*: %s", l.status.name, fmt.Sprintf(msg, args...))
+}
+
+// retry calls fn again while slack rate limits it, waiting as long as slack asks between attempts
+func retry(fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		var rateLimited *slack.RateLimitedError
+		if !errors.As(err, &rateLimited) || attempt >= slackMaxRetries {
+			return err
+		}
+		slackSleep(rateLimited.RetryAfter)
+	}
+}
+
+func markdown(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.MarkdownType, text, false, false)
+}
+
+func textSection(text string) slack.Block {
+	return slack.NewSectionBlock(markdown(truncate(text, slackMaxTextLength)), nil, nil)
+}
+
+// truncate shortens text to at most max bytes, closing a code block if it cut one off
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+
+	const ellipsis = "…"
+	const codeFence = "```"
+	cut := max - len(ellipsis) - len(codeFence)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	text = text[:cut] + ellipsis
+	if strings.Count(text, codeFence)%2 == 1 {
+		text += codeFence
+	}
+	return text
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SlackLogger_summary(t *testing.T) {
+	req := require.New(t)
+
+	l := &SlackLogger{printToLogs: true, isSilent: true}
+	req.Equal("", l.summary())
+
+	l.ForCluster("cluster-a").Info("Creating VPC")
+	l.ForCluster("cluster-b").Error(errors.New("boom"))
+	l.ForCluster("cluster-c").FinishSpinnerWithError()
+	l.ForCluster("cluster-a").WithFields(Fields{Grid: "grid"}).Info("Creating nodes")
+
+	req.Equal(`Clusters:
+:white_check_mark: cluster-a
+:x: cluster-b: boom
+:x: cluster-c`, l.summary())
+
+	blocks := l.summaryBlocks()
+	req.Len(blocks, 2)
+	fields := blocks[1].(*slack.SectionBlock).Fields
+	req.Len(fields, 6)
+	req.Equal("cluster-b", fields[2].Text)
+	req.Equal(":x: boom", fields[3].Text)
+}
+
+func Test_retry(t *testing.T) {
+	defer func(sleep func(time.Duration)) { slackSleep = sleep }(slackSleep)
+	slept := []time.Duration{}
+	slackSleep = func(d time.Duration) { slept = append(slept, d) }
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   string
+	}{
+		{
+			name:      "succeeds",
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "rate limited then succeeds",
+			errs:      []error{&slack.RateLimitedError{RetryAfter: time.Second}, nil},
+			wantCalls: 2,
+		},
+		{
+			name:      "other errors aren't retried",
+			errs:      []error{errors.New("channel_not_found")},
+			wantCalls: 1,
+			wantErr:   "channel_not_found",
+		},
+		{
+			name:      "gives up",
+			errs:      []error{&slack.RateLimitedError{RetryAfter: time.Second}},
+			wantCalls: slackMaxRetries + 1,
+			wantErr:   "slack rate limit exceeded, retry after 1s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			slept = []time.Duration{}
+
+			calls := 0
+			err := retry(func() error {
+				i := calls
+				if i >= len(tt.errs) {
+					i = len(tt.errs) - 1
+				}
+				calls++
+				return tt.errs[i]
+			})
+			if tt.wantErr != "" {
+				req.EqualError(err, tt.wantErr)
+			} else {
+				req.NoError(err)
+			}
+			req.Equal(tt.wantCalls, calls)
+			req.Len(slept, tt.wantCalls-1)
+		})
+	}
+}
+
+func Test_truncate(t *testing.T) {
+	req := require.New(t)
+
+	req.Equal("short", truncate("short", 10))
+	req.Equal("abcdefgh…", truncate(strings.Repeat("abcdefghij", 2), 14))
+	req.Equal("```abcde…```", truncate("```"+strings.Repeat("abcdefghij", 2)+"```", 14))
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+)
+
+const (
+	teamsColorInfo  = "0076d7"
+	teamsColorOK    = "2eb886"
+	teamsColorError = "d70000"
+)
+
+// teamsCard is the legacy actionable message card accepted by Teams incoming webhooks
+type teamsCard struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor"`
+	Title      string `json:"title,omitempty"`
+	Text       string `json:"text"`
+}
+
+// NewTeamsLogger posts each event as a card to a Teams incoming webhook
+func NewTeamsLogger(loggerSpec *types.TeamsLoggerSpec) (Logger, error) {
+	l, err := newWebhookLogger(loggerSpec.URL, types.ValueOrValueFrom{})
+	if err != nil {
+		return nil, err
+	}
+	l.encode = encodeTeamsCard
+	return l, nil
+}
+
+func encodeTeamsCard(event WebhookEvent) ([]byte, error) {
+	card := teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: teamsColorInfo,
+		Text:       event.Message,
+	}
+
+	switch event.Type {
+	case EventError:
+		card.ThemeColor = teamsColorError
+		card.Text = event.Error
+	case EventTestResult:
+		if event.Passed != nil && *event.Passed {
+			card.ThemeColor = teamsColorOK
+			card.Text = fmt.Sprintf("Test of %s passed", event.App)
+		} else {
+			card.ThemeColor = teamsColorError
+			card.Text = fmt.Sprintf("Test of %s failed: %s", event.App, event.Error)
+		}
+	}
+
+	if event.Cluster != "" {
+		card.Title = event.Cluster
+	}
+
+	card.Summary = card.Text
+	return json.Marshal(card)
+}
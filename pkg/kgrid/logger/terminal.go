@@ -13,7 +13,7 @@ import (
 type TerminalLogger struct {
 	spinnerStopCh chan bool
 	spinnerMsg    string
-	spinnerArgs   []interface{}
+	fields        Fields
 	isSilent      bool
 	isVerbose     bool
 }
@@ -36,13 +36,32 @@ func (l *TerminalLogger) Verbose() {
 	l.isVerbose = true
 }
 
+func (l *TerminalLogger) WithFields(fields Fields) Logger {
+	return &TerminalLogger{
+		fields:    l.fields.merge(fields),
+		isSilent:  l.isSilent,
+		isVerbose: l.isVerbose,
+	}
+}
+
+func (l *TerminalLogger) ForCluster(name string) Logger {
+	return l.WithFields(Fields{Cluster: name})
+}
+
+// format prefixes the message with the cluster it's about, if there is one
+func (l *TerminalLogger) format(msg string, args ...interface{}) string {
+	if l.fields.Cluster == "" {
+		return fmt.Sprintf(msg, args...)
+	}
+	return fmt.Sprintf("[%s] %s", l.fields.Cluster, fmt.Sprintf(msg, args...))
+}
+
 func (l *TerminalLogger) StartThread(msg string, args ...interface{}) {
 	if l == nil || l.isSilent {
 		return
 	}
 
-	fmt.Printf(msg, args...)
-	fmt.Println("")
+	fmt.Println(l.format(msg, args...))
 }
 
 func (l *TerminalLogger) FinishThread(msg string, args ...interface{}) {
@@ -50,8 +69,7 @@ func (l *TerminalLogger) FinishThread(msg string, args ...interface{}) {
 		return
 	}
 
-	fmt.Printf(msg, args...)
-	fmt.Println("")
+	fmt.Println(l.format(msg, args...))
 }
 
 func (l *TerminalLogger) Debug(msg string, args ...interface{}) {
@@ -60,7 +78,7 @@ func (l *TerminalLogger) Debug(msg string, args ...interface{}) {
 	}
 
 	fmt.Printf("    ")
-	fmt.Println(fmt.Sprintf(msg, args...))
+	fmt.Println(l.format(msg, args...))
 	fmt.Println("")
 }
 
@@ -70,7 +88,7 @@ func (l *TerminalLogger) Info(msg string, args ...interface{}) {
 	}
 
 	fmt.Printf("    ")
-	fmt.Println(fmt.Sprintf(msg, args...))
+	fmt.Println(l.format(msg, args...))
 	fmt.Println("")
 }
 
@@ -85,7 +103,7 @@ func (l *TerminalLogger) ActionWithoutSpinner(msg string, args ...interface{}) {
 	}
 
 	fmt.Printf("  • ")
-	fmt.Println(fmt.Sprintf(msg, args...))
+	fmt.Println(l.format(msg, args...))
 }
 
 func (l *TerminalLogger) ChildActionWithoutSpinner(msg string, args ...interface{}) {
@@ -94,7 +112,7 @@ func (l *TerminalLogger) ChildActionWithoutSpinner(msg string, args ...interface
 	}
 
 	fmt.Printf("    • ")
-	fmt.Println(fmt.Sprintf(msg, args...))
+	fmt.Println(l.format(msg, args...))
 }
 
 func (l *TerminalLogger) ActionWithSpinner(msg string, args ...interface{}) {
@@ -102,8 +120,18 @@ func (l *TerminalLogger) ActionWithSpinner(msg string, args ...interface{}) {
 		return
 	}
 
+	msg = l.format(msg, args...)
+	l.spinnerMsg = msg
+
+	// clusters run in parallel, so their spinners would draw over each other
+	if l.fields.Cluster != "" {
+		fmt.Printf("  • ")
+		fmt.Println(msg)
+		return
+	}
+
 	fmt.Printf("  • ")
-	fmt.Printf(msg, args...)
+	fmt.Print(msg)
 
 	if isatty.IsTerminal(os.Stdout.Fd()) {
 		s := spin.New()
@@ -111,8 +139,6 @@ func (l *TerminalLogger) ActionWithSpinner(msg string, args ...interface{}) {
 		fmt.Printf(" %s", s.Next())
 
 		l.spinnerStopCh = make(chan bool)
-		l.spinnerMsg = msg
-		l.spinnerArgs = args
 
 		go func() {
 			for {
@@ -122,7 +148,7 @@ func (l *TerminalLogger) ActionWithSpinner(msg string, args ...interface{}) {
 				case <-time.After(time.Millisecond * 100):
 					fmt.Printf("\r")
 					fmt.Printf("  • ")
-					fmt.Printf(msg, args...)
+					fmt.Print(msg)
 					fmt.Printf(" %s", s.Next())
 				}
 			}
@@ -135,8 +161,18 @@ func (l *TerminalLogger) ChildActionWithSpinner(msg string, args ...interface{})
 		return
 	}
 
+	msg = l.format(msg, args...)
+	l.spinnerMsg = msg
+
+	// clusters run in parallel, so their spinners would draw over each other
+	if l.fields.Cluster != "" {
+		fmt.Printf("    • ")
+		fmt.Println(msg)
+		return
+	}
+
 	fmt.Printf("    • ")
-	fmt.Printf(msg, args...)
+	fmt.Print(msg)
 
 	if isatty.IsTerminal(os.Stdout.Fd()) {
 		s := spin.New()
@@ -144,8 +180,6 @@ func (l *TerminalLogger) ChildActionWithSpinner(msg string, args ...interface{})
 		fmt.Printf(" %s", s.Next())
 
 		l.spinnerStopCh = make(chan bool)
-		l.spinnerMsg = msg
-		l.spinnerArgs = args
 
 		go func() {
 			for {
@@ -155,7 +189,7 @@ func (l *TerminalLogger) ChildActionWithSpinner(msg string, args ...interface{})
 				case <-time.After(time.Millisecond * 100):
 					fmt.Printf("\r")
 					fmt.Printf("    • ")
-					fmt.Printf(msg, args...)
+					fmt.Print(msg)
 					fmt.Printf(" %s", s.Next())
 				}
 			}
@@ -172,14 +206,11 @@ func (l *TerminalLogger) FinishChildSpinner() {
 
 	fmt.Printf("\r")
 	fmt.Printf("    • ")
-	fmt.Printf(l.spinnerMsg, l.spinnerArgs...)
+	fmt.Print(l.spinnerMsg)
 	green.Printf(" ✓")
 	fmt.Printf("  \n")
 
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		l.spinnerStopCh <- true
-		close(l.spinnerStopCh)
-	}
+	l.stopSpinner()
 }
 
 func (l *TerminalLogger) FinishSpinner() {
@@ -191,14 +222,11 @@ func (l *TerminalLogger) FinishSpinner() {
 
 	fmt.Printf("\r")
 	fmt.Printf("  • ")
-	fmt.Printf(l.spinnerMsg, l.spinnerArgs...)
+	fmt.Print(l.spinnerMsg)
 	green.Printf(" ✓")
 	fmt.Printf("  \n")
 
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		l.spinnerStopCh <- true
-		close(l.spinnerStopCh)
-	}
+	l.stopSpinner()
 }
 
 func (l *TerminalLogger) FinishSpinnerWithError() {
@@ -210,14 +238,28 @@ func (l *TerminalLogger) FinishSpinnerWithError() {
 
 	fmt.Printf("\r")
 	fmt.Printf("  • ")
-	fmt.Printf(l.spinnerMsg, l.spinnerArgs...)
+	fmt.Print(l.spinnerMsg)
 	red.Printf(" ✗")
 	fmt.Printf("  \n")
 
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		l.spinnerStopCh <- true
-		close(l.spinnerStopCh)
+	l.stopSpinner()
+}
+
+func (l *TerminalLogger) stopSpinner() {
+	if l.spinnerStopCh == nil {
+		return
 	}
+	l.spinnerStopCh <- true
+	close(l.spinnerStopCh)
+	l.spinnerStopCh = nil
+}
+
+func (l *TerminalLogger) Attach(title string, path string) {
+	l.Info("%s: %s", title, path)
+}
+
+// TestResult doesn't print anything, the thread's finish message already shows the result
+func (l *TerminalLogger) TestResult(app string, err error) {
 }
 
 func (l *TerminalLogger) Error(err error) {
@@ -227,5 +269,5 @@ func (l *TerminalLogger) Error(err error) {
 
 	c := color.New(color.FgHiRed)
 	c.Printf("  • ")
-	c.Println(fmt.Sprintf("%#v", err))
+	c.Println(l.format("%#v", err))
 }
@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+)
+
+const (
+	// SignatureHeader holds the hex HMAC-SHA256 of the request body, prefixed with sha256=
+	SignatureHeader = "X-Kgrid-Signature"
+
+	EventThreadStart  = "thread.start"
+	EventThreadFinish = "thread.finish"
+	EventDebug        = "debug"
+	EventInfo         = "info"
+	EventError        = "error"
+	EventTestResult   = "test.result"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// WebhookEvent is the body of each request sent by WebhookLogger
+type WebhookEvent struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"ts"`
+	Message   string `json:"msg,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// App and Passed are set on test results
+	App    string `json:"app,omitempty"`
+	Passed *bool  `json:"passed,omitempty"`
+	Fields
+}
+
+// WebhookLogger POSTs threads, info, errors, and test results to a URL.
+// Spinners and actions are left to the console.
+type WebhookLogger struct {
+	url    string
+	secret string
+	client *http.Client
+	now    func() time.Time
+	// encode turns an event into the request body, so other services can be built on the webhook
+	encode func(WebhookEvent) ([]byte, error)
+
+	fields Fields
+
+	isSilent  bool
+	isVerbose bool
+}
+
+// NewWebhookLogger sends each event to the URL in the spec as JSON
+func NewWebhookLogger(loggerSpec *types.WebhookLoggerSpec) (Logger, error) {
+	l, err := newWebhookLogger(loggerSpec.URL, loggerSpec.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func newWebhookLogger(url types.ValueOrValueFrom, secret types.ValueOrValueFrom) (*WebhookLogger, error) {
+	u, err := url.String()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook url")
+	}
+	if u == "" {
+		return nil, errors.New("webhook url is required")
+	}
+
+	l := &WebhookLogger{
+		url:    u,
+		client: &http.Client{Timeout: webhookTimeout},
+		now:    time.Now,
+		encode: func(event WebhookEvent) ([]byte, error) {
+			return json.Marshal(event)
+		},
+	}
+
+	if secret.Value != "" || secret.ValueFrom != nil {
+		s, err := secret.String()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get webhook secret")
+		}
+		l.secret = s
+	}
+
+	return l, nil
+}
+
+func (l *WebhookLogger) Silence() {
+	if l == nil {
+		return
+	}
+	l.isSilent = true
+}
+
+func (l *WebhookLogger) Verbose() {
+	if l == nil {
+		return
+	}
+	l.isVerbose = true
+}
+
+func (l *WebhookLogger) WithFields(fields Fields) Logger {
+	child := *l
+	child.fields = l.fields.merge(fields)
+	return &child
+}
+
+func (l *WebhookLogger) ForCluster(name string) Logger {
+	return l.WithFields(Fields{Cluster: name})
+}
+
+func (l *WebhookLogger) StartThread(msg string, args ...interface{}) {
+	l.send(WebhookEvent{Type: EventThreadStart, Message: fmt.Sprintf(msg, args...)})
+}
+
+func (l *WebhookLogger) FinishThread(msg string, args ...interface{}) {
+	l.send(WebhookEvent{Type: EventThreadFinish, Message: fmt.Sprintf(msg, args...)})
+}
+
+func (l *WebhookLogger) Debug(msg string, args ...interface{}) {
+	if l == nil || !l.isVerbose {
+		return
+	}
+	l.send(WebhookEvent{Type: EventDebug, Message: fmt.Sprintf(msg, args...)})
+}
+
+func (l *WebhookLogger) Info(msg string, args ...interface{}) {
+	l.send(WebhookEvent{Type: EventInfo, Message: fmt.Sprintf(msg, args...)})
+}
+
+func (l *WebhookLogger) ActionWithoutSpinner(msg string, args ...interface{}) {
+}
+
+func (l *WebhookLogger) ChildActionWithoutSpinner(msg string, args ...interface{}) {
+}
+
+func (l *WebhookLogger) ActionWithSpinner(msg string, args ...interface{}) {
+}
+
+func (l *WebhookLogger) ChildActionWithSpinner(msg string, args ...interface{}) {
+}
+
+func (l *WebhookLogger) FinishChildSpinner() {
+}
+
+func (l *WebhookLogger) FinishSpinner() {
+}
+
+func (l *WebhookLogger) FinishSpinnerWithError() {
+}
+
+func (l *WebhookLogger) Error(err error) {
+	l.send(WebhookEvent{Type: EventError, Error: err.Error()})
+}
+
+func (l *WebhookLogger) TestResult(app string, err error) {
+	passed := err == nil
+	event := WebhookEvent{Type: EventTestResult, App: app, Passed: &passed}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	l.send(event)
+}
+
+// Attach only sends the path, since the file is local to where kgrid runs
+func (l *WebhookLogger) Attach(title string, path string) {
+	l.send(WebhookEvent{Type: EventInfo, Message: fmt.Sprintf("%s: %s", title, path)})
+}
+
+func (l *WebhookLogger) send(event WebhookEvent) {
+	if l == nil || l.isSilent {
+		return
+	}
+
+	event.Timestamp = l.now().UTC().Format(time.RFC3339Nano)
+	event.Fields = l.fields
+
+	body, err := l.encode(event)
+	if err != nil {
+		log.Println("failed to encode webhook event", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		log.Println("failed to create webhook request", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(l.secret, body))
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		log.Println("failed to send webhook event", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("unexpected status code sending webhook event: %d", resp.StatusCode)
+	}
+}
+
+// Sign returns the signature header value for body, for receivers to compare against
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedRequest struct {
+	body      string
+	signature string
+}
+
+func newWebhookServer(t *testing.T) (*httptest.Server, func() []receivedRequest) {
+	var mu sync.Mutex
+	received := []receivedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, receivedRequest{body: string(body), signature: r.Header.Get(SignatureHeader)})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
+
+func Test_WebhookLogger(t *testing.T) {
+	req := require.New(t)
+
+	server, received := newWebhookServer(t)
+	l, err := NewWebhookLogger(&types.WebhookLoggerSpec{
+		URL:    types.ValueOrValueFrom{Value: server.URL},
+		Secret: types.ValueOrValueFrom{Value: "secret"},
+	})
+	req.NoError(err)
+	l.(*WebhookLogger).now = func() time.Time { return time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC) }
+
+	l.StartThread("Testing app %s", "app")
+	l.ActionWithSpinner("Creating cluster")
+	l.Debug("not verbose")
+	l.ForCluster("cluster-a").Error(errors.New("boom"))
+	l.TestResult("app", errors.New("boom"))
+
+	want := []string{
+		`{"type":"thread.start","ts":"2022-08-01T12:00:00Z","msg":"Testing app app"}`,
+		`{"type":"error","ts":"2022-08-01T12:00:00Z","error":"boom","cluster":"cluster-a"}`,
+		`{"type":"test.result","ts":"2022-08-01T12:00:00Z","error":"boom","app":"app","passed":false}`,
+	}
+	got := received()
+	req.Len(got, len(want))
+	for i := range want {
+		req.Equal(want[i], got[i].body)
+		req.Equal(Sign("secret", []byte(want[i])), got[i].signature)
+	}
+}
+
+func Test_TeamsLogger(t *testing.T) {
+	req := require.New(t)
+
+	server, received := newWebhookServer(t)
+	l, err := NewTeamsLogger(&types.TeamsLoggerSpec{URL: types.ValueOrValueFrom{Value: server.URL}})
+	req.NoError(err)
+
+	l.ForCluster("cluster-a").Info("Creating VPC")
+	l.TestResult("app", nil)
+
+	got := received()
+	req.Len(got, 2)
+	req.Equal(`{"@type":"MessageCard","@context":"https://schema.org/extensions","summary":"Creating VPC","themeColor":"0076d7","title":"cluster-a","text":"Creating VPC"}`, got[0].body)
+	req.Equal(`{"@type":"MessageCard","@context":"https://schema.org/extensions","summary":"Test of app passed","themeColor":"2eb886","text":"Test of app passed"}`, got[1].body)
+	req.Empty(got[0].signature)
+}
@@ -0,0 +1,247 @@
+package outcome
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	kgridv1alpha1 "github.com/replicatedhq/kgrid/apis/kgrid/v1alpha1"
+	kgridclientset "github.com/replicatedhq/kgrid/pkg/client/kgridclientset/typed/kgrid/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// TestPodLabelKey is set to the test id on every test pod
+	TestPodLabelKey = "kgrid.replicated.com/test"
+
+	// the values of these can be anything, so they're annotations rather than labels
+	RunAnnotationKey     = "kgrid.replicated.com/run"
+	AppAnnotationKey     = "kgrid.replicated.com/app"
+	ClusterAnnotationKey = "kgrid.replicated.com/cluster"
+	VersionAnnotationKey = "kgrid.replicated.com/version"
+)
+
+// TestPodName is the name of the pod that runs the test
+func TestPodName(testID string) string {
+	return fmt.Sprintf("test-%s", testID)
+}
+
+// TestStatus is a single test, from an outcome or from its pod
+type TestStatus struct {
+	ID         string                   `json:"id"`
+	RunID      string                   `json:"runId,omitempty"`
+	App        string                   `json:"app,omitempty"`
+	Cluster    string                   `json:"cluster,omitempty"`
+	Version    string                   `json:"version,omitempty"`
+	Result     kgridv1alpha1.TestResult `json:"result"`
+	StartedAt  *metav1.Time             `json:"startedAt,omitempty"`
+	FinishedAt *metav1.Time             `json:"finishedAt,omitempty"`
+}
+
+// Duration is how long the test ran for, or has been running for if it hasn't finished
+func (t TestStatus) Duration(now time.Time) (time.Duration, bool) {
+	if t.StartedAt == nil {
+		return 0, false
+	}
+	if t.FinishedAt != nil {
+		return t.FinishedAt.Sub(t.StartedAt.Time), true
+	}
+	return now.Sub(t.StartedAt.Time), true
+}
+
+// TestResultFromPod maps the phase of a test pod to a test result
+func TestResultFromPod(pod *corev1.Pod) kgridv1alpha1.TestResult {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return kgridv1alpha1.TestResultPass
+	case corev1.PodPending, corev1.PodRunning:
+		return kgridv1alpha1.TestResultPending
+	case corev1.PodFailed:
+		return kgridv1alpha1.TestResultFail
+	case corev1.PodUnknown:
+		return kgridv1alpha1.TestResultUnknown
+	}
+
+	return kgridv1alpha1.TestResultUnknown
+}
+
+// TestTimesFromPod returns when the test pod started, and when its last container finished
+func TestTimesFromPod(pod *corev1.Pod) (startedAt *metav1.Time, finishedAt *metav1.Time) {
+	startedAt = pod.Status.StartTime
+
+	if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+		return startedAt, nil
+	}
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.State.Terminated == nil {
+			continue
+		}
+		if finishedAt == nil || finishedAt.Before(&s.State.Terminated.FinishedAt) {
+			t := s.State.Terminated.FinishedAt
+			finishedAt = &t
+		}
+	}
+
+	return startedAt, finishedAt
+}
+
+// TestStatusFromPod describes the test run by pod
+func TestStatusFromPod(pod *corev1.Pod) TestStatus {
+	startedAt, finishedAt := TestTimesFromPod(pod)
+	return TestStatus{
+		ID:         pod.Labels[TestPodLabelKey],
+		RunID:      pod.Annotations[RunAnnotationKey],
+		App:        pod.Annotations[AppAnnotationKey],
+		Cluster:    pod.Annotations[ClusterAnnotationKey],
+		Version:    pod.Annotations[VersionAnnotationKey],
+		Result:     TestResultFromPod(pod),
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+	}
+}
+
+// TestStatuses returns the tests in the outcome
+func TestStatuses(o *kgridv1alpha1.Outcome) []TestStatus {
+	tests := []TestStatus{}
+	for _, t := range o.Tests {
+		tests = append(tests, TestStatus{
+			ID:         t.ID,
+			RunID:      o.Name,
+			App:        t.App,
+			Cluster:    t.Cluster,
+			Version:    t.Version,
+			Result:     t.Result,
+			StartedAt:  t.StartedAt,
+			FinishedAt: t.FinishedAt,
+		})
+	}
+	return tests
+}
+
+// IsFinalized returns true when none of the tests are still pending
+func IsFinalized(tests []TestStatus) bool {
+	for _, t := range tests {
+		if t.Result == kgridv1alpha1.TestResultPending || t.Result == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ListTests returns the tests that have pods in the namespace, optionally only those for app.
+// The resource version of the list can be passed to WatchTests to watch for changes after it.
+func ListTests(ctx context.Context, clientset kubernetes.Interface, namespace string, app string) ([]TestStatus, string, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: TestPodLabelKey,
+	})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to list test pods")
+	}
+
+	tests := []TestStatus{}
+	for i := range pods.Items {
+		t := TestStatusFromPod(&pods.Items[i])
+		if app != "" && t.App != app {
+			continue
+		}
+		tests = append(tests, t)
+	}
+
+	return tests, pods.ResourceVersion, nil
+}
+
+// WatchTests calls fn each time a test pod changes after resourceVersion, until ctx is cancelled or fn returns done
+func WatchTests(ctx context.Context, clientset kubernetes.Interface, namespace string, app string, resourceVersion string, fn func(t TestStatus) (done bool, err error)) error {
+	return watchUntil(ctx, resourceVersion, func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+		return clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
+			LabelSelector:   TestPodLabelKey,
+			ResourceVersion: resourceVersion,
+		})
+	}, func(obj runtime.Object) (bool, error) {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return false, nil
+		}
+		t := TestStatusFromPod(pod)
+		if app != "" && t.App != app {
+			return false, nil
+		}
+		return fn(t)
+	})
+}
+
+// WatchOutcomes calls fn each time an outcome changes after resourceVersion, until ctx is cancelled or fn returns done.
+// Only the outcome named name is watched if it's not empty.
+func WatchOutcomes(ctx context.Context, client kgridclientset.KgridV1alpha1Interface, namespace string, name string, resourceVersion string, fn func(o *kgridv1alpha1.Outcome) (done bool, err error)) error {
+	return watchUntil(ctx, resourceVersion, func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+		opts := metav1.ListOptions{
+			ResourceVersion: resourceVersion,
+		}
+		if name != "" {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}
+		return client.Outcomes(namespace).Watch(ctx, opts)
+	}, func(obj runtime.Object) (bool, error) {
+		o, ok := obj.(*kgridv1alpha1.Outcome)
+		if !ok {
+			return false, nil
+		}
+		return fn(o)
+	})
+}
+
+// watchUntil restarts the watch from the last event it saw when the server closes it, which it does every few minutes
+func watchUntil(ctx context.Context, resourceVersion string, start func(ctx context.Context, resourceVersion string) (watch.Interface, error), fn func(obj runtime.Object) (bool, error)) error {
+	for {
+		w, err := start(ctx, resourceVersion)
+		if err != nil {
+			return errors.Wrap(err, "failed to start watch")
+		}
+
+		done, err := handleEvents(ctx, w, &resourceVersion, fn)
+		w.Stop()
+		if err != nil || done {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func handleEvents(ctx context.Context, w watch.Interface, resourceVersion *string, fn func(obj runtime.Object) (bool, error)) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+			case watch.Error:
+				return false, errors.Wrap(kuberneteserrors.FromObject(event.Object), "watch failed")
+			default:
+				continue
+			}
+
+			if m, err := meta.Accessor(event.Object); err == nil {
+				*resourceVersion = m.GetResourceVersion()
+			}
+
+			done, err := fn(event.Object)
+			if err != nil || done {
+				return done, err
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package outcome
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kgridv1alpha1 "github.com/replicatedhq/kgrid/apis/kgrid/v1alpha1"
+	kgridfake "github.com/replicatedhq/kgrid/pkg/client/kgridclientset/fake"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_TestStatusFromPod(t *testing.T) {
+	startedAt := metav1.NewTime(time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC))
+	finishedAt := metav1.NewTime(startedAt.Add(15 * time.Minute))
+
+	tests := []struct {
+		name         string
+		phase        corev1.PodPhase
+		want         TestStatus
+		wantDuration time.Duration
+	}{
+		{
+			name:  "running",
+			phase: corev1.PodRunning,
+			want: TestStatus{
+				ID:        "abc",
+				RunID:     "run",
+				App:       "app",
+				Cluster:   "cluster",
+				Version:   "1.0.0",
+				Result:    kgridv1alpha1.TestResultPending,
+				StartedAt: &startedAt,
+			},
+			wantDuration: 20 * time.Minute,
+		},
+		{
+			name:  "failed",
+			phase: corev1.PodFailed,
+			want: TestStatus{
+				ID:         "abc",
+				RunID:      "run",
+				App:        "app",
+				Cluster:    "cluster",
+				Version:    "1.0.0",
+				Result:     kgridv1alpha1.TestResultFail,
+				StartedAt:  &startedAt,
+				FinishedAt: &finishedAt,
+			},
+			wantDuration: 15 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{TestPodLabelKey: "abc"},
+					Annotations: map[string]string{
+						RunAnnotationKey:     "run",
+						AppAnnotationKey:     "app",
+						ClusterAnnotationKey: "cluster",
+						VersionAnnotationKey: "1.0.0",
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:     tt.phase,
+					StartTime: &startedAt,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: finishedAt}}},
+					},
+				},
+			}
+
+			got := TestStatusFromPod(pod)
+			req.Equal(tt.want, got)
+
+			d, ok := got.Duration(startedAt.Add(20 * time.Minute))
+			req.True(ok)
+			req.Equal(tt.wantDuration, d)
+		})
+	}
+}
+
+func Test_WatchOutcomes(t *testing.T) {
+	req := require.New(t)
+
+	clientset := kgridfake.NewSimpleClientset()
+	watcher := watch.NewFake()
+	clientset.PrependWatchReactor("outcomes", k8stesting.DefaultWatchReactor(watcher, nil))
+
+	outcome := func(results ...kgridv1alpha1.TestResult) *kgridv1alpha1.Outcome {
+		o := &kgridv1alpha1.Outcome{ObjectMeta: metav1.ObjectMeta{Name: "run"}}
+		for _, r := range results {
+			o.Tests = append(o.Tests, kgridv1alpha1.Test{Result: r})
+		}
+		return o
+	}
+
+	go func() {
+		watcher.Modify(outcome(kgridv1alpha1.TestResultPass, kgridv1alpha1.TestResultPending))
+		watcher.Modify(outcome(kgridv1alpha1.TestResultPass, kgridv1alpha1.TestResultFail))
+	}()
+
+	seen := [][]kgridv1alpha1.TestResult{}
+	err := WatchOutcomes(context.Background(), clientset.KgridV1alpha1(), "default", "run", "", func(o *kgridv1alpha1.Outcome) (bool, error) {
+		results := []kgridv1alpha1.TestResult{}
+		for _, t := range o.Tests {
+			results = append(results, t.Result)
+		}
+		seen = append(seen, results)
+		return IsFinalized(TestStatuses(o)), nil
+	})
+	req.NoError(err)
+	req.Equal([][]kgridv1alpha1.TestResult{
+		{kgridv1alpha1.TestResultPass, kgridv1alpha1.TestResultPending},
+		{kgridv1alpha1.TestResultPass, kgridv1alpha1.TestResultFail},
+	}, seen)
+}
@@ -0,0 +1,83 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Task is a unit of work run by Run
+type Task[T any] func(ctx context.Context) (T, error)
+
+// Result is the outcome of a single task
+type Result[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// Run runs all tasks, with at most limit running at the same time, and waits for them to finish.
+// A limit of 0 or less runs every task at once.
+// Results are returned in the same order as tasks. Tasks that had not started when ctx was cancelled
+// are not run, and their result carries the context error.
+func Run[T any](ctx context.Context, limit int, tasks []Task[T]) []Result[T] {
+	results := make([]Result[T], len(tasks))
+	if limit <= 0 || limit > len(tasks) {
+		limit = len(tasks)
+	}
+
+	sem := make(chan struct{}, limit)
+	wg := sync.WaitGroup{}
+
+	for i, task := range tasks {
+		results[i].Index = i
+
+		select {
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		// a slot may have freed up at the same time as ctx was cancelled
+		if err := ctx.Err(); err != nil {
+			<-sem
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, task Task[T]) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i].Value, results[i].Err = runTask(ctx, task)
+		}(i, task)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// Errors returns the errors of all failed results, in task order
+func Errors[T any](results []Result[T]) []error {
+	errs := []error{}
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errs
+}
+
+func runTask[T any](ctx context.Context, task Task[T]) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("task panicked: %v", r)
+		}
+	}()
+
+	return task(ctx)
+}
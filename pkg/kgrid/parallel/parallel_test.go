@@ -0,0 +1,137 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Run(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		count int
+	}{
+		{
+			name:  "unlimited",
+			limit: 0,
+			count: 10,
+		},
+		{
+			name:  "limit of 1",
+			limit: 1,
+			count: 5,
+		},
+		{
+			name:  "limit of 3",
+			limit: 3,
+			count: 10,
+		},
+		{
+			name:  "limit above task count",
+			limit: 20,
+			count: 4,
+		},
+		{
+			name:  "no tasks",
+			limit: 2,
+			count: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := require.New(t)
+
+			var running, maxRunning int32
+			tasks := []Task[string]{}
+			for i := 0; i < test.count; i++ {
+				i := i
+				tasks = append(tasks, func(ctx context.Context) (string, error) {
+					n := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						m := atomic.LoadInt32(&maxRunning)
+						if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+							break
+						}
+					}
+
+					time.Sleep(10 * time.Millisecond)
+
+					if i%2 == 1 {
+						return "", errors.Errorf("task %d failed", i)
+					}
+					return fmt.Sprintf("task %d", i), nil
+				})
+			}
+
+			results := Run(context.Background(), test.limit, tasks)
+			req.Len(results, test.count)
+
+			for i, result := range results {
+				assert.Equal(t, i, result.Index)
+				if i%2 == 1 {
+					assert.EqualError(t, result.Err, fmt.Sprintf("task %d failed", i))
+				} else {
+					assert.NoError(t, result.Err)
+					assert.Equal(t, fmt.Sprintf("task %d", i), result.Value)
+				}
+			}
+
+			assert.Len(t, Errors(results), test.count/2)
+
+			if test.limit > 0 {
+				assert.LessOrEqual(t, int(maxRunning), test.limit)
+			}
+		})
+	}
+}
+
+func Test_RunCancelled(t *testing.T) {
+	req := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var started int32
+	tasks := []Task[int]{}
+	for i := 0; i < 5; i++ {
+		tasks = append(tasks, func(ctx context.Context) (int, error) {
+			atomic.AddInt32(&started, 1)
+			cancel()
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+	}
+
+	results := Run(ctx, 1, tasks)
+	req.Len(results, 5)
+	req.Equal(int32(1), atomic.LoadInt32(&started))
+	for _, result := range results {
+		req.ErrorIs(result.Err, context.Canceled)
+	}
+}
+
+func Test_RunPanic(t *testing.T) {
+	req := require.New(t)
+
+	results := Run(context.Background(), 0, []Task[int]{
+		func(ctx context.Context) (int, error) {
+			panic("boom")
+		},
+		func(ctx context.Context) (int, error) {
+			return 1, nil
+		},
+	})
+
+	req.EqualError(results[0].Err, "task panicked: boom")
+	req.NoError(results[1].Err)
+	req.Equal(1, results[1].Value)
+}
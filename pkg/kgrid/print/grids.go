@@ -0,0 +1,78 @@
+package print
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/grid/types"
+)
+
+const redacted = "REDACTED"
+
+var GridsTable = Table[*types.GridConfig]{
+	Empty: "No grids found",
+	Columns: []Column[*types.GridConfig]{
+		{Header: "NAME", Value: func(g *types.GridConfig) string { return g.Name }},
+		{Header: "CLUSTERS", Value: func(g *types.GridConfig) string {
+			created := 0
+			for _, c := range g.ClusterConfigs {
+				if !c.Creating {
+					created++
+				}
+			}
+			return fmt.Sprintf("%d/%d", created, len(g.ClusterConfigs))
+		}},
+		{Header: "PROVIDERS", Wide: true, Value: func(g *types.GridConfig) string {
+			return uniqueValues(g.ClusterConfigs, func(c *types.ClusterConfig) string { return c.Provider })
+		}},
+		{Header: "REGIONS", Wide: true, Value: func(g *types.GridConfig) string {
+			return uniqueValues(g.ClusterConfigs, func(c *types.ClusterConfig) string { return c.Region })
+		}},
+	},
+}
+
+// RedactGridConfig returns a copy of the grid config without kubeconfigs or credential values.
+// Credentials that reference env vars are kept, since they don't contain the secret.
+func RedactGridConfig(g *types.GridConfig) *types.GridConfig {
+	redactedGrid := &types.GridConfig{
+		Name: g.Name,
+	}
+
+	for _, c := range g.ClusterConfigs {
+		redactedCluster := *c
+		redactedCluster.Kubeconfig = ""
+		redactedCluster.AccessKeyID = redactValue(c.AccessKeyID)
+		redactedCluster.SecretAccessKey = redactValue(c.SecretAccessKey)
+		redactedGrid.ClusterConfigs = append(redactedGrid.ClusterConfigs, &redactedCluster)
+	}
+
+	return redactedGrid
+}
+
+func redactValue(v *types.ValueOrValueFrom) *types.ValueOrValueFrom {
+	if v == nil || v.Value == "" {
+		return v
+	}
+
+	return &types.ValueOrValueFrom{
+		Value:     redacted,
+		ValueFrom: v.ValueFrom,
+	}
+}
+
+func uniqueValues(clusterConfigs []*types.ClusterConfig, value func(*types.ClusterConfig) string) string {
+	seen := map[string]bool{}
+	values := []string{}
+	for _, c := range clusterConfigs {
+		v := value(c)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	return strings.Join(values, ",")
+}
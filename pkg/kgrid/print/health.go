@@ -0,0 +1,66 @@
+package print
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/replicatedhq/kgrid/pkg/kgrid/health"
+)
+
+// ClusterHealthTable prints cluster health, with ages relative to now
+func ClusterHealthTable(now time.Time) Table[*health.ClusterHealth] {
+	return Table[*health.ClusterHealth]{
+		Empty: "No clusters found",
+		Columns: []Column[*health.ClusterHealth]{
+			{Header: "NAME", Value: func(h *health.ClusterHealth) string { return h.Name }},
+			{Header: "STATUS", Value: func(h *health.ClusterHealth) string { return h.Status() }},
+			{Header: "VERSION", Value: func(h *health.ClusterHealth) string { return h.ServerVersion }},
+			{Header: "NODES", Value: func(h *health.ClusterHealth) string {
+				if !h.Reachable {
+					return ""
+				}
+				return fmt.Sprintf("%d/%d", h.ReadyNodes, h.Nodes)
+			}},
+			{Header: "PROVIDER", Value: func(h *health.ClusterHealth) string { return h.Provider }},
+			{Header: "REGION", Value: func(h *health.ClusterHealth) string { return h.Region }},
+			{Header: "AGE", Value: func(h *health.ClusterHealth) string {
+				if h.CreatedAt == nil {
+					return age(now, time.Time{})
+				}
+				return age(now, h.CreatedAt.Time)
+			}},
+			{Header: "APPS", Wide: true, Value: func(h *health.ClusterHealth) string {
+				apps := []string{}
+				for _, a := range h.Apps {
+					apps = append(apps, fmt.Sprintf("%s (%s)", a.Slug, a.State))
+				}
+				return strings.Join(apps, ",")
+			}},
+			{Header: "ERROR", Wide: true, Value: func(h *health.ClusterHealth) string {
+				if h.Error != "" {
+					return h.Error
+				}
+				return h.AppsError
+			}},
+		},
+	}
+}
+
+// AppRow is a deployed app along with the cluster it was found in
+type AppRow struct {
+	Cluster   string
+	Namespace string
+	Slug      string
+	State     string
+}
+
+var AppsTable = Table[AppRow]{
+	Empty: "No apps found",
+	Columns: []Column[AppRow]{
+		{Header: "CLUSTER", Value: func(a AppRow) string { return a.Cluster }},
+		{Header: "NAMESPACE", Value: func(a AppRow) string { return a.Namespace }},
+		{Header: "APP", Value: func(a AppRow) string { return a.Slug }},
+		{Header: "STATE", Value: func(a AppRow) string { return a.State }},
+	},
+}
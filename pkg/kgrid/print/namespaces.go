@@ -0,0 +1,28 @@
+package print
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceRow is a namespace along with the cluster it's in
+type NamespaceRow struct {
+	Cluster   string      `json:"cluster"`
+	Name      string      `json:"name"`
+	Status    string      `json:"status"`
+	CreatedAt metav1.Time `json:"createdAt"`
+}
+
+// NamespacesTable prints namespaces, with ages relative to now
+func NamespacesTable(now time.Time) Table[NamespaceRow] {
+	return Table[NamespaceRow]{
+		Empty: "No namespaces found",
+		Columns: []Column[NamespaceRow]{
+			{Header: "CLUSTER", Value: func(n NamespaceRow) string { return n.Cluster }},
+			{Header: "NAME", Value: func(n NamespaceRow) string { return n.Name }},
+			{Header: "STATUS", Value: func(n NamespaceRow) string { return n.Status }},
+			{Header: "AGE", Value: func(n NamespaceRow) string { return age(now, n.CreatedAt.Time) }},
+		},
+	}
+}
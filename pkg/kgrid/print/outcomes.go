@@ -0,0 +1,81 @@
+package print
+
+import (
+	"fmt"
+	"time"
+
+	kgridv1alpha1 "github.com/replicatedhq/kgrid/apis/kgrid/v1alpha1"
+	"github.com/replicatedhq/kgrid/pkg/kgrid/outcome"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	outcomeRunning   = "Running"
+	outcomeFinalized = "Finalized"
+)
+
+// OutcomesTable prints one row per run, with ages relative to now
+func OutcomesTable(now time.Time) Table[*kgridv1alpha1.Outcome] {
+	countResult := func(result kgridv1alpha1.TestResult) func(o *kgridv1alpha1.Outcome) string {
+		return func(o *kgridv1alpha1.Outcome) string {
+			count := 0
+			for _, t := range o.Tests {
+				if t.Result == result {
+					count++
+				}
+			}
+			return fmt.Sprintf("%d", count)
+		}
+	}
+
+	return Table[*kgridv1alpha1.Outcome]{
+		Empty: "No outcomes found",
+		Columns: []Column[*kgridv1alpha1.Outcome]{
+			{Header: "RUN", Value: func(o *kgridv1alpha1.Outcome) string { return o.Name }},
+			{Header: "STATUS", Value: OutcomeStatus},
+			{Header: "TESTS", Value: func(o *kgridv1alpha1.Outcome) string { return fmt.Sprintf("%d", len(o.Tests)) }},
+			{Header: "PASS", Value: countResult(kgridv1alpha1.TestResultPass)},
+			{Header: "FAIL", Value: countResult(kgridv1alpha1.TestResultFail)},
+			{Header: "PENDING", Value: countResult(kgridv1alpha1.TestResultPending)},
+			{Header: "UNKNOWN", Wide: true, Value: countResult(kgridv1alpha1.TestResultUnknown)},
+			{Header: "AGE", Value: func(o *kgridv1alpha1.Outcome) string { return age(now, o.CreationTimestamp.Time) }},
+		},
+	}
+}
+
+// OutcomeStatus is Finalized once no tests in the run are pending
+func OutcomeStatus(o *kgridv1alpha1.Outcome) string {
+	if outcome.IsFinalized(outcome.TestStatuses(o)) {
+		return outcomeFinalized
+	}
+	return outcomeRunning
+}
+
+// TestsTable prints one row per test, with running durations relative to now
+func TestsTable(now time.Time) Table[outcome.TestStatus] {
+	return Table[outcome.TestStatus]{
+		Empty: "No tests found",
+		Columns: []Column[outcome.TestStatus]{
+			{Header: "APP", Value: func(t outcome.TestStatus) string { return t.App }},
+			{Header: "CLUSTER", Value: func(t outcome.TestStatus) string { return t.Cluster }},
+			{Header: "VERSION", Value: func(t outcome.TestStatus) string { return t.Version }},
+			{Header: "RESULT", Value: func(t outcome.TestStatus) string { return string(t.Result) }},
+			{Header: "DURATION", Value: func(t outcome.TestStatus) string {
+				d, ok := t.Duration(now)
+				if !ok {
+					return ""
+				}
+				return duration.HumanDuration(d)
+			}},
+			{Header: "RUN", Wide: true, Value: func(t outcome.TestStatus) string { return t.RunID }},
+			{Header: "ID", Wide: true, Value: func(t outcome.TestStatus) string { return t.ID }},
+		},
+	}
+}
+
+func age(now time.Time, t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(t))
+}
@@ -1,8 +1,16 @@
 package print
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -12,6 +20,158 @@ const (
 	padChar  = ' '
 )
 
+const (
+	FormatTable      = "table"
+	FormatWide       = "wide"
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatGoTemplate = "go-template"
+
+	// FormatUsage is the help text for an --output flag
+	FormatUsage = "Output format: table, wide, json, yaml, or go-template=<template>"
+)
+
 func NewTabWriter() *tabwriter.Writer {
-	return tabwriter.NewWriter(os.Stdout, minWidth, tabWidth, padding, padChar, tabwriter.TabIndent)
+	return NewTabWriterTo(os.Stdout)
+}
+
+func NewTabWriterTo(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, minWidth, tabWidth, padding, padChar, tabwriter.TabIndent)
+}
+
+// Table describes how to print a list of T as a table
+type Table[T any] struct {
+	Columns []Column[T]
+	// Empty is printed instead of the table when there are no rows
+	Empty string
+}
+
+type Column[T any] struct {
+	Header string
+	// Wide columns are only printed with -o wide
+	Wide  bool
+	Value func(T) string
+}
+
+// PrintList writes items in the format. Tables have one row per item,
+// and json, yaml and go-template are given the whole list.
+func PrintList[T any](w io.Writer, format string, items []T, table Table[T]) error {
+	switch format {
+	case "", FormatTable, FormatWide:
+		return printTable(w, items, table, format == FormatWide)
+	}
+
+	if items == nil {
+		items = []T{}
+	}
+	return printData(w, format, items)
+}
+
+// PrintObject writes a single object in the format. Tables are written by text.
+func PrintObject(w io.Writer, format string, obj interface{}, text func(io.Writer) error) error {
+	switch format {
+	case "", FormatTable, FormatWide:
+		return text(w)
+	}
+
+	return printData(w, format, obj)
+}
+
+// ValidateFormat returns an error if format isn't one of the supported output formats
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatTable, FormatWide, FormatJSON, FormatYAML:
+		return nil
+	}
+	if _, ok := goTemplate(format); ok {
+		return nil
+	}
+
+	return errors.Errorf("unknown output format %q, must be one of table, wide, json, yaml, or go-template=<template>", format)
+}
+
+func printTable[T any](w io.Writer, items []T, table Table[T], wide bool) error {
+	if len(items) == 0 && table.Empty != "" {
+		_, err := fmt.Fprintln(w, table.Empty)
+		return err
+	}
+
+	columns := []Column[T]{}
+	for _, c := range table.Columns {
+		if c.Wide && !wide {
+			continue
+		}
+		columns = append(columns, c)
+	}
+
+	tw := NewTabWriterTo(w)
+
+	headers := []string{}
+	for _, c := range columns {
+		headers = append(headers, c.Header)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, item := range items {
+		values := []string{}
+		for _, c := range columns {
+			values = append(values, c.Value(item))
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func printData(w io.Writer, format string, data interface{}) error {
+	switch format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(data, "", "    ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal json")
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+
+	case FormatYAML:
+		b, err := yaml.Marshal(data)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal yaml")
+		}
+		_, err = w.Write(b)
+		return err
+	}
+
+	text, ok := goTemplate(format)
+	if !ok {
+		return ValidateFormat(format)
+	}
+
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse go-template")
+	}
+
+	// execute against the json representation, so templates use the same field names as -o json
+	b, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal json")
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return errors.Wrap(err, "failed to unmarshal json")
+	}
+
+	if err := tmpl.Execute(w, generic); err != nil {
+		return errors.Wrap(err, "failed to execute go-template")
+	}
+
+	return nil
+}
+
+func goTemplate(format string) (string, bool) {
+	if !strings.HasPrefix(format, FormatGoTemplate+"=") {
+		return "", false
+	}
+	return strings.TrimPrefix(format, FormatGoTemplate+"="), true
 }